@@ -6,6 +6,7 @@ set -e
 mkdir -p bin
 
 echo "Building Goku CLI..."
-go build -o bin/goku cmd/goku/main.go
+# sqlite_fts5 compiles in SQLite's full-text search, used to rank search results
+go build -tags sqlite_fts5 -o bin/goku cmd/goku/main.go
 
 echo "Build completed successfully. Binary is located at bin/goku"
\ No newline at end of file
@@ -6,6 +6,9 @@ import (
 	"github.com/fallrising/goku-cli/internal/bookmarks"
 	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"os/signal"
 )
 
 func AddCommand() *cli.Command {
@@ -14,11 +17,22 @@ func AddCommand() *cli.Command {
 		Usage:       "Add a new bookmark",
 		Description: "Add a new bookmark to the database. If title, description, or tags are not provided, Goku will attempt to fetch this information from the webpage.",
 
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "url", Required: true},
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{Name: "url"},
+			&cli.StringFlag{
+				Name:  "from-file",
+				Usage: "Add every URL in this file, one per line, instead of --url; - reads standard input",
+			},
+			&cli.IntFlag{
+				Name:    "workers",
+				Aliases: []string{"w"},
+				Usage:   "Number of bookmarks fetched concurrently with --from-file --fetch",
+				Value:   5,
+			},
 			&cli.StringFlag{Name: "title"},
 			&cli.StringFlag{Name: "description"},
 			&cli.StringSliceFlag{Name: "tags"},
+			&cli.StringFlag{Name: "kind", Usage: "Kind of bookmark (page, video, document, repo, image); inferred when omitted"},
 			&cli.BoolFlag{
 				Name:    "fetch",
 				Aliases: []string{"F"},
@@ -26,20 +40,36 @@ func AddCommand() *cli.Command {
 					"Examples:\n" +
 					"  goku add --url https://example.com\n" +
 					"  goku add --url https://example.com --title \"Example Site\" --tags tag1,tag2\n" +
-					"  goku add --url https://example.com --fetch",
+					"  goku add --url https://example.com --fetch\n" +
+					"  goku add --from-file urls.txt --tags reading --fetch\n" +
+					"  pbpaste | goku add --from-file -",
 				Value: false, // Disabled by default
 			},
-		},
+			waybackFlag(),
+			skipNonHTMLFlag(),
+		}, fetchFlags()...),
 		ArgsUsage: "<url>",
 		Action: func(c *cli.Context) error {
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			switch {
+			case c.IsSet("url") && c.IsSet("from-file"):
+				return fmt.Errorf("--url and --from-file cannot be combined")
+			case c.IsSet("from-file"):
+				return addFromFile(c, bookmarkService, c.String("from-file"))
+			case !c.IsSet("url"):
+				return fmt.Errorf("either --url or --from-file is required")
+			}
+
 			bookmark := &models.Bookmark{
 				URL:         c.String("url"),
 				Title:       c.String("title"),
 				Description: c.String("description"),
 				Tags:        c.StringSlice("tags"),
+				Kind:        c.String("kind"),
 			}
 			fetchData := c.Bool("fetch")
+			bookmarkService.SetFetchConfig(fetchConfigFromFlags(c))
+			bookmarkService.SetWaybackFallback(c.Bool("wayback"))
 			ctx := context.WithValue(context.Background(), "fetchData", fetchData)
 			err := bookmarkService.CreateBookmark(ctx, bookmark)
 			if err != nil {
@@ -50,3 +80,50 @@ func AddCommand() *cli.Command {
 		},
 	}
 }
+
+// addFromFile adds a bookmark for each URL listed in path, or on standard input when
+// path is "-", with the tags and kind given on the command line. A URL that fails is
+// reported without stopping the others.
+func addFromFile(c *cli.Context, bookmarkService *bookmarks.BookmarkService, path string) error {
+	if c.IsSet("title") || c.IsSet("description") {
+		return fmt.Errorf("--title and --description cannot be combined with --from-file")
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := openFile(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	bookmarkService.SetFetchConfig(fetchConfigFromFlags(c))
+	bookmarkService.SetWaybackFallback(c.Bool("wayback"))
+
+	// Ctrl-C stops adding, aborting fetches that are in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = context.WithValue(ctx, "numWorkers", c.Int("workers"))
+	ctx = context.WithValue(ctx, "fetchData", c.Bool("fetch"))
+
+	template := models.Bookmark{Tags: c.StringSlice("tags"), Kind: c.String("kind")}
+	result, err := bookmarkService.AddFromList(ctx, input, template)
+	if result != nil {
+		fmt.Printf("Added %d of %d bookmarks.\n", result.Created, result.Total)
+		if result.Duplicates > 0 {
+			fmt.Printf("  already stored: %d\n", result.Duplicates)
+		}
+		if result.FetchFailed > 0 {
+			fmt.Printf("  added without metadata: %d\n", result.FetchFailed)
+		}
+		for _, failure := range result.Errors {
+			fmt.Printf("  failed: %v\n", failure)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add bookmarks: %w", err)
+	}
+	return nil
+}
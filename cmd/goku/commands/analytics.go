@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func AnalyticsCommand() *cli.Command {
+	return &cli.Command{
+		Name: "analytics",
+		Usage: "Sync bookmarks into DuckDB and print the statistics it computes\n\n" +
+			"Examples:\n" +
+			"  goku analytics\n" +
+			"  goku analytics --duckdb-path /tmp/analysis.duckdb --days 30 --format json",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "duckdb-path",
+				Usage: "DuckDB file to sync into and analyze (default: the global --duckdb file)",
+			},
+		}, statisticsFlags()...),
+		Action: func(c *cli.Context) error {
+			asJSON, err := jsonFromFlags(c)
+			if err != nil {
+				return err
+			}
+
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			days, top := c.Int("days"), c.Int("top")
+			stats, err := bookmarkService.Analyze(c.Context, c.String("duckdb-path"), days, top)
+			if err != nil {
+				return fmt.Errorf("failed to analyze bookmarks: %w", err)
+			}
+
+			if asJSON {
+				return printJSON(stats)
+			}
+
+			printStatistics(stats, days, top)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func AuditCommand() *cli.Command {
+	return &cli.Command{
+		Name: "audit",
+		Usage: "Check stored bookmarks for problems\n\n" +
+			"Examples:\n" +
+			"  goku audit --non-html\n" +
+			"  goku audit --non-html --workers 10",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "non-html",
+				Usage: "List bookmarks whose URL is not served as an HTML page",
+			},
+			&cli.IntFlag{
+				Name:    "workers",
+				Aliases: []string{"w"},
+				Usage:   "Number of URLs to check concurrently",
+				Value:   5,
+			},
+		}, fetchFlags()...),
+		Action: func(c *cli.Context) error {
+			if !c.Bool("non-html") {
+				return fmt.Errorf("please specify an audit to run, e.g. --non-html")
+			}
+
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			bookmarkService.SetFetchConfig(fetchConfigFromFlags(c))
+
+			results, err := bookmarkService.AuditNonHTML(context.Background(), c.Int("workers"))
+			if err != nil {
+				return fmt.Errorf("failed to audit bookmarks: %w", err)
+			}
+			if len(results) == 0 {
+				fmt.Println("All bookmarks are served as HTML.")
+				return nil
+			}
+
+			fmt.Printf("Found %d bookmark(s) not served as HTML:\n", len(results))
+			for _, result := range results {
+				b := result.Bookmark
+				if result.Err != nil {
+					fmt.Printf("ID: %d, URL: %s, Error: %v\n", b.ID, b.URL, result.Err)
+					continue
+				}
+				contentType := result.ContentType
+				if contentType == "" {
+					contentType = "unknown"
+				}
+				fmt.Printf("ID: %d, URL: %s, Content-Type: %s, Kind: %s\n", b.ID, b.URL, contentType, b.Kind)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func BackupCommand() *cli.Command {
+	return &cli.Command{
+		Name: "backup",
+		Usage: "Write a consistent copy of the bookmark database, safe while goku is in use\n\n" +
+			"Example:\n" +
+			"  goku backup --output goku-backup.db",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "output",
+				Aliases:  []string{"o"},
+				Usage:    "Path of the backup file to create; it must not exist",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+			outputPath := c.String("output")
+			if err := bookmarkService.Backup(c.Context, outputPath); err != nil {
+				return err
+			}
+			fmt.Printf("Database backed up to %s\n", outputPath)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func CheckCommand() *cli.Command {
+	return &cli.Command{
+		Name: "check",
+		Usage: "Check bookmarks for dead links, grouped by host\n\n" +
+			"Examples:\n" +
+			"  goku check\n" +
+			"  goku check --workers 10 --list-dead",
+		Flags: append([]cli.Flag{
+			&cli.IntFlag{
+				Name:    "workers",
+				Aliases: []string{"w"},
+				Usage:   "Number of URLs to check concurrently",
+				Value:   5,
+			},
+			&cli.BoolFlag{
+				Name:  "list-dead",
+				Usage: "List each unreachable link under its host",
+			},
+		}, fetchFlags()...),
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			bookmarkService.SetFetchConfig(fetchConfigFromFlags(c))
+
+			reports, err := bookmarkService.CheckLinks(context.Background(), c.Int("workers"))
+			if err != nil {
+				return fmt.Errorf("failed to check links: %w", err)
+			}
+
+			var reachable, unreachable int
+			for _, report := range reports {
+				reachable += report.Reachable
+				unreachable += report.Unreachable
+			}
+			fmt.Printf("Checked %d link(s) on %d host(s): %d reachable, %d unreachable\n",
+				reachable+unreachable, len(reports), reachable, unreachable)
+
+			for _, report := range reports {
+				switch {
+				case report.AllDead():
+					fmt.Printf("%s: all %d link(s) are dead\n", report.Hostname, report.Unreachable)
+				case report.Unreachable > 0:
+					fmt.Printf("%s: %d reachable, %d unreachable\n", report.Hostname, report.Reachable, report.Unreachable)
+				default:
+					continue
+				}
+				if c.Bool("list-dead") {
+					for _, dead := range report.Dead {
+						fmt.Printf("  ID: %d, URL: %s, Error: %v\n", dead.Bookmark.ID, dead.Bookmark.URL, dead.Err)
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
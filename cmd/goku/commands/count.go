@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+	"os"
+)
+
+func CountCommand() *cli.Command {
+	return &cli.Command{
+		Name: "count",
+		Usage: "Print bookmark, tag and host totals\n\n" +
+			"Examples:\n" +
+			"  goku count\n" +
+			"  goku count --json\n" +
+			"  goku count --tag go --host github.com",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the totals as a single JSON object, e.g. for dashboards",
+			},
+			&cli.StringSliceFlag{Name: "tag", Usage: "Only count bookmarks with this tag (repeatable); prints just the number"},
+			&cli.StringFlag{Name: "host", Aliases: []string{"hostname"}, Usage: "Only count bookmarks on this host; prints just the number"},
+			&cli.BoolFlag{Name: "verbose", Usage: "With --tag or --host, describe the number instead of printing it alone"},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			if c.IsSet("tag") || c.IsSet("host") {
+				if c.Bool("json") {
+					return fmt.Errorf("--json cannot be combined with --tag or --host")
+				}
+				filter := models.BookmarkFilter{Tags: c.StringSlice("tag"), Host: c.String("host")}
+				count, err := bookmarkService.CountBookmarksFiltered(context.Background(), filter)
+				if err != nil {
+					return err
+				}
+				if c.Bool("verbose") {
+					fmt.Printf("%d bookmark(s) match\n", count)
+				} else {
+					fmt.Println(count)
+				}
+				return nil
+			}
+
+			summary, err := bookmarkService.GetCountSummary(context.Background())
+			if err != nil {
+				return fmt.Errorf("failed to count bookmarks: %w", err)
+			}
+
+			if c.Bool("json") {
+				if err := json.NewEncoder(os.Stdout).Encode(summary); err != nil {
+					return fmt.Errorf("failed to write JSON: %w", err)
+				}
+				return nil
+			}
+
+			fmt.Printf("Total: %d\n", summary.Total)
+			fmt.Printf("Accessible: %d\n", summary.Accessible)
+			fmt.Printf("Inaccessible: %d\n", summary.Inaccessible)
+			fmt.Printf("Tags: %d\n", summary.Tags)
+			fmt.Printf("Hosts: %d\n", summary.Hosts)
+			return nil
+		},
+	}
+}
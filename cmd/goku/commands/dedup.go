@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+func DedupCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "dedup",
+		Aliases: []string{"dedupe"},
+		Usage: "Merge duplicate bookmarks\n\n" +
+			"Examples:\n" +
+			"  goku dedup --url --dry-run\n" +
+			"  goku dedup --scheme\n" +
+			"  goku dedup --scheme --dry-run\n" +
+			"  goku dedup --scheme --interactive\n" +
+			"  goku dedup --similar --threshold 0.8",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "scheme",
+				Usage: "Merge bookmarks that differ only in http:// versus https://, keeping the https one",
+			},
+			&cli.BoolFlag{
+				Name:  "url",
+				Usage: "Merge bookmarks whose URLs match once scheme, www., tracking parameters, fragment and trailing slashes are ignored, keeping the oldest",
+			},
+			&cli.BoolFlag{
+				Name:  "similar",
+				Usage: "List clusters of bookmarks with similar URLs or titles for review, without changing anything",
+			},
+			&cli.Float64Flag{
+				Name:  "threshold",
+				Value: bookmarks.DefaultSimilarityThreshold,
+				Usage: "Similarity, from 0 to 1, that --similar requires between URLs or titles",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be deleted without changing anything",
+			},
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "Confirm each merge: y(es), n(o), a(ll remaining), q(uit)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			if c.Bool("similar") {
+				groups, err := bookmarkService.FindSimilarBookmarks(c.Context, c.Float64("threshold"))
+				if err != nil {
+					return fmt.Errorf("failed to find similar bookmarks: %w", err)
+				}
+				printSimilarGroups(groups)
+				return nil
+			}
+			if c.Bool("scheme") == c.Bool("url") {
+				return fmt.Errorf("choose one of --scheme, --url or --similar")
+			}
+
+			find := bookmarkService.FindSchemeDuplicates
+			if c.Bool("url") {
+				find = bookmarkService.FindURLDuplicates
+			}
+			groups, err := find(c.Context)
+			if err != nil {
+				return fmt.Errorf("failed to find duplicates: %w", err)
+			}
+
+			if c.Bool("dry-run") {
+				var removed []*models.Bookmark
+				for _, group := range groups {
+					removed = append(removed, group.Remove...)
+				}
+				sample := removed
+				if len(sample) > previewSampleSize {
+					sample = sample[:previewSampleSize]
+				}
+				printRemovalPreview(len(removed), sample)
+				return nil
+			}
+
+			interactive := c.Bool("interactive")
+			merged, deleted := 0, 0
+			for _, group := range groups {
+				if interactive {
+					printDuplicateGroup(group)
+					answer := promptChange()
+					if answer == "q" {
+						break
+					}
+					if answer == "n" {
+						continue
+					}
+					if answer == "a" {
+						interactive = false
+					}
+				}
+
+				if err := bookmarkService.MergeDuplicates(c.Context, group); err != nil {
+					return fmt.Errorf("failed to merge duplicates of %s: %w", group.Keep.URL, err)
+				}
+				merged++
+				deleted += len(group.Remove)
+			}
+
+			fmt.Printf("Merged %d duplicate group(s), deleting %d bookmark(s).\n", merged, deleted)
+			return nil
+		},
+	}
+}
+
+// printDuplicateGroup shows a proposed merge: the bookmarks that would be deleted and
+// the bookmark that is kept, before and after.
+func printDuplicateGroup(group bookmarks.DuplicateGroup) {
+	for _, bookmark := range group.Remove {
+		fmt.Printf("- delete %d: %s [%s]\n", bookmark.ID, bookmark.URL, strings.Join(bookmark.Tags, ","))
+	}
+	fmt.Printf("  keep   %d: %s [%s] -> [%s]\n", group.Keep.ID, group.Keep.URL, strings.Join(group.Keep.Tags, ","), strings.Join(group.Tags, ","))
+}
+
+// printSimilarGroups lists clusters of similar bookmarks for the user to review.
+func printSimilarGroups(groups []bookmarks.SimilarGroup) {
+	if len(groups) == 0 {
+		fmt.Println("No similar bookmarks found.")
+		return
+	}
+	for i, group := range groups {
+		fmt.Printf("Cluster %d (%d bookmarks):\n", i+1, len(group.Bookmarks))
+		for _, bookmark := range group.Bookmarks {
+			fmt.Printf("  %d: %s - %s\n", bookmark.ID, bookmark.URL, bookmark.Title)
+		}
+	}
+	fmt.Printf("Found %d cluster(s) of similar bookmarks. Nothing was deleted; use `goku delete --id` to remove the ones you don't need.\n", len(groups))
+}
+
+// promptChange asks whether to apply a change and returns "y", "n", "a" or "q".
+// Anything else counts as no.
+func promptChange() string {
+	fmt.Print("Apply this change? (y/n/a/q): ")
+	var response string
+	fmt.Scanln(&response)
+	switch response = strings.ToLower(strings.TrimSpace(response)); response {
+	case "y", "a", "q":
+		return response
+	default:
+		return "n"
+	}
+}
@@ -10,7 +10,7 @@ import (
 func DeleteCommand() *cli.Command {
 	return &cli.Command{
 		Name: "delete",
-		Usage: "Delete a bookmark\n\n" +
+		Usage: "Move a bookmark to the trash\n\n" +
 			"Example:\n" +
 			"  goku delete --id 123",
 		Flags: []cli.Flag{
@@ -22,7 +22,7 @@ func DeleteCommand() *cli.Command {
 			if err != nil {
 				return fmt.Errorf("failed to delete bookmark: %w", err)
 			}
-			fmt.Println("Bookmark deleted successfully")
+			fmt.Println("Bookmark moved to the trash; restore it with goku restore --id", c.Int64("id"))
 			return nil
 		},
 	}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+func DiffCommand() *cli.Command {
+	return &cli.Command{
+		Name: "diff",
+		Usage: "Compare two JSON exports and list added, removed and modified bookmarks\n\n" +
+			"Examples:\n" +
+			"  goku diff --old backup-monday.json --new backup-tuesday.json\n" +
+			"  goku diff --old before.jsonl --new after.jsonl",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "old", Required: true, Usage: "Earlier export (JSON array or JSON Lines)"},
+			&cli.StringFlag{Name: "new", Required: true, Usage: "Later export (JSON array or JSON Lines)"},
+		},
+		Action: func(c *cli.Context) error {
+			oldBookmarks, err := readExportFile(c.String("old"))
+			if err != nil {
+				return err
+			}
+			newBookmarks, err := readExportFile(c.String("new"))
+			if err != nil {
+				return err
+			}
+
+			diff := bookmarks.DiffExports(oldBookmarks, newBookmarks)
+			for _, bookmark := range diff.Added {
+				fmt.Printf("+ %s\n", bookmark.URL)
+			}
+			for _, bookmark := range diff.Removed {
+				fmt.Printf("- %s\n", bookmark.URL)
+			}
+			for _, change := range diff.Modified {
+				fmt.Printf("~ %s (%s)\n", change.New.URL, strings.Join(change.Fields, ", "))
+			}
+			fmt.Printf("%d added, %d removed, %d modified\n", len(diff.Added), len(diff.Removed), len(diff.Modified))
+			return nil
+		},
+	}
+}
+
+// readExportFile reads the bookmarks of a JSON or JSON Lines export file.
+func readExportFile(path string) ([]*models.Bookmark, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	exported, err := bookmarks.ReadExport(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return exported, nil
+}
@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+// editableBookmark holds the fields edit writes to the temporary file.
+type editableBookmark struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Kind        string   `json:"kind"`
+}
+
+func EditCommand() *cli.Command {
+	return &cli.Command{
+		Name: "edit",
+		Usage: "Edit a bookmark's fields as JSON in $EDITOR\n\n" +
+			"Examples:\n" +
+			"  goku edit --id 123\n" +
+			"  EDITOR=\"code --wait\" goku edit --id 123",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "id", Required: true},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			ctx := context.WithValue(context.Background(), "fetchData", false)
+
+			id := c.Int64("id")
+			bookmark, err := bookmarkService.GetBookmark(ctx, id)
+			if err != nil {
+				return fmt.Errorf("failed to get bookmark %d: %w", id, err)
+			}
+
+			original := editableBookmark{
+				URL:         bookmark.URL,
+				Title:       bookmark.Title,
+				Description: bookmark.Description,
+				Tags:        bookmark.Tags,
+				Kind:        bookmark.Kind,
+			}
+			edited, err := editInEditor(original)
+			if err != nil {
+				return err
+			}
+
+			changes := editedFields(id, original, edited)
+			if changes == nil {
+				fmt.Println("No changes made.")
+				return nil
+			}
+			if err := bookmarkService.UpdateBookmark(ctx, changes); err != nil {
+				return fmt.Errorf("failed to update bookmark: %w", err)
+			}
+			fmt.Println("Bookmark updated successfully")
+			return nil
+		},
+	}
+}
+
+// editInEditor writes original to a temporary JSON file, opens it in $VISUAL or
+// $EDITOR (vi if neither is set) and reads the saved file back.
+func editInEditor(original editableBookmark) (editableBookmark, error) {
+	data, err := json.MarshalIndent(original, "", "  ")
+	if err != nil {
+		return editableBookmark{}, fmt.Errorf("failed to encode bookmark: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "goku-edit-*.json")
+	if err != nil {
+		return editableBookmark{}, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return editableBookmark{}, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return editableBookmark{}, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may carry arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return editableBookmark{}, fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return editableBookmark{}, fmt.Errorf("failed to read edited file: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var edited editableBookmark
+	if err := decoder.Decode(&edited); err != nil {
+		return editableBookmark{}, fmt.Errorf("failed to parse edited bookmark: %w", err)
+	}
+	return edited, nil
+}
+
+// editedFields returns an update for bookmark id carrying only the fields that differ
+// between original and edited, or nil if none do. Fields left empty are not changed,
+// as with update.
+func editedFields(id int64, original, edited editableBookmark) *models.Bookmark {
+	// An unchanged URL is passed as is, so UpdateBookmark doesn't treat it as new
+	changes := &models.Bookmark{ID: id, URL: original.URL}
+	changed := false
+	if edited.URL = strings.TrimSpace(edited.URL); edited.URL != "" && edited.URL != original.URL {
+		changes.URL = edited.URL
+		changed = true
+	}
+	if edited.Title != "" && edited.Title != original.Title {
+		changes.Title = edited.Title
+		changed = true
+	}
+	if edited.Description != "" && edited.Description != original.Description {
+		changes.Description = edited.Description
+		changed = true
+	}
+	if len(edited.Tags) > 0 && !sameTags(edited.Tags, original.Tags) {
+		changes.Tags = edited.Tags
+		changed = true
+	}
+	if edited.Kind != "" && edited.Kind != original.Kind {
+		changes.Kind = edited.Kind
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return changes
+}
+
+// sameTags reports whether a and b hold the same tags once normalized, in any order,
+// as UpdateBookmark compares them.
+func sameTags(a, b []string) bool {
+	a, b = models.NormalizeTags(a), models.NormalizeTags(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
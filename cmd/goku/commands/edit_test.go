@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestEditedFields(t *testing.T) {
+	original := editableBookmark{
+		URL:         "https://example.com",
+		Title:       "Example",
+		Description: "An example",
+		Tags:        []string{"go", "web"},
+		Kind:        "article",
+	}
+
+	tests := []struct {
+		name   string
+		edited editableBookmark
+		want   *models.Bookmark
+	}{
+		{"unchanged", original, nil},
+		{
+			"emptied fields are ignored",
+			editableBookmark{URL: " ", Title: "", Description: "", Tags: nil, Kind: ""},
+			nil,
+		},
+		{
+			"reordered tags",
+			editableBookmark{URL: original.URL, Title: original.Title, Description: original.Description, Tags: []string{"Web", "go"}, Kind: original.Kind},
+			nil,
+		},
+		{
+			"changed URL",
+			editableBookmark{URL: " https://example.org ", Title: original.Title, Description: original.Description, Tags: original.Tags, Kind: original.Kind},
+			&models.Bookmark{ID: 7, URL: "https://example.org"},
+		},
+		{
+			"changed title and tags",
+			editableBookmark{URL: original.URL, Title: "New title", Description: "", Tags: []string{"go"}, Kind: original.Kind},
+			&models.Bookmark{ID: 7, URL: original.URL, Title: "New title", Tags: []string{"go"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := editedFields(7, original, tt.edited)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("editedFields = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
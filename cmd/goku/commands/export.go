@@ -1,36 +1,95 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 func ExportCommand() *cli.Command {
 	return &cli.Command{
 		Name: "export",
-		Usage: "Export bookmarks to HTML format\n\n" +
+		Usage: "Export bookmarks to HTML, JSON, JSON Lines, CSV or Markdown format\n\n" +
 			"Examples:\n" +
 			"  goku export\n" +
-			"  goku export --output bookmarks.html",
+			"  goku export --output bookmarks.html\n" +
+			"  goku export --sample 100 --output review.html\n" +
+			"  goku export --output backup.json\n" +
+			"  goku export --format jsonl --output bookmarks.jsonl\n" +
+			"  goku export --output bookmarks.csv\n" +
+			"  goku export --output bookmarks.md\n" +
+			"  goku export --order created --output bookmarks.html",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:    "output",
 				Aliases: []string{"o"},
 				Usage:   "Output file path (default: stdout)",
 			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format: html, json (one array), jsonl (one JSON object per line), csv or markdown (a section per tag); defaults to the output file's extension, or html",
+			},
+			&cli.IntFlag{
+				Name:  "sample",
+				Usage: "Export only this many randomly chosen bookmarks (html only)",
+			},
+			&cli.StringFlag{
+				Name:  "order",
+				Usage: "Order of the exported bookmarks: id (insertion order) or created (creation time)",
+				Value: string(models.SortByID),
+			},
 		},
 		Action: func(c *cli.Context) error {
-			fmt.Println("Exporting bookmarks...")
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
-			html, err := bookmarkService.ExportToHTML(context.Background())
+			outputPath := c.String("output")
+			order := models.SortField(c.String("order"))
+			if order != models.SortByID && order != models.SortByCreated {
+				return fmt.Errorf("unsupported export order: %s", order)
+			}
+
+			format := exportFormat(c.String("format"), outputPath)
+			if format != "html" && c.IsSet("sample") {
+				return fmt.Errorf("--sample is only supported with --format html")
+			}
+			switch format {
+			case "html":
+			case "json":
+				return exportJSON(bookmarkService, outputPath, order)
+			case "jsonl":
+				return exportStream(outputPath, func(w io.Writer) error {
+					return bookmarkService.ExportToJSONL(context.Background(), w, order)
+				})
+			case "markdown":
+				return exportMarkdown(bookmarkService, outputPath, order)
+			case "csv":
+				return exportStream(outputPath, func(w io.Writer) error {
+					return bookmarkService.ExportToCSV(context.Background(), w, order)
+				})
+			default:
+				return fmt.Errorf("unsupported export format: %s", format)
+			}
+
+			if outputPath != "" {
+				fmt.Println("Exporting bookmarks...")
+			}
+			var html string
+			var err error
+			if c.IsSet("sample") {
+				html, err = bookmarkService.ExportSampleToHTML(context.Background(), c.Int("sample"))
+			} else {
+				html, err = bookmarkService.ExportToHTML(context.Background(), order)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to export bookmarks: %w", err)
 			}
 
-			outputPath := c.String("output")
 			if outputPath == "" {
 				// Write to stdout if no output file specified
 				fmt.Println(html)
@@ -47,3 +106,98 @@ func ExportCommand() *cli.Command {
 		},
 	}
 }
+
+// exportFormat returns the export format named by --format or, if it is empty, by the
+// extension of outputPath. It falls back to html.
+func exportFormat(format, outputPath string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".json":
+		return "json"
+	case ".jsonl":
+		return "jsonl"
+	case ".csv":
+		return "csv"
+	case ".md", ".markdown":
+		return "markdown"
+	default:
+		return "html"
+	}
+}
+
+// exportJSON writes every bookmark, in the given order, as a JSON array to outputPath,
+// or stdout when it is empty.
+func exportJSON(bookmarkService *bookmarks.BookmarkService, outputPath string, order models.SortField) error {
+	content, err := bookmarkService.ExportToJSON(context.Background(), order)
+	if err != nil {
+		return fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+
+	if outputPath == "" {
+		fmt.Println(content)
+		return nil
+	}
+	if err := os.WriteFile(outputPath, []byte(content+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+	fmt.Printf("Bookmarks exported to %s\n", outputPath)
+	return nil
+}
+
+// exportMarkdown writes every bookmark, in the given order, as a Markdown document to
+// outputPath, or stdout when it is empty.
+func exportMarkdown(bookmarkService *bookmarks.BookmarkService, outputPath string, order models.SortField) error {
+	content, err := bookmarkService.ExportToMarkdown(context.Background(), order)
+	if err != nil {
+		return fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+
+	if outputPath == "" {
+		fmt.Print(content)
+		return nil
+	}
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+	fmt.Printf("Bookmarks exported to %s\n", outputPath)
+	return nil
+}
+
+// exportStream writes an export produced by export to outputPath, or stdout when it is
+// empty. A failed export to a file removes the partial file.
+func exportStream(outputPath string, export func(w io.Writer) error) error {
+	if outputPath == "" {
+		w := bufio.NewWriter(os.Stdout)
+		err := export(w)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export bookmarks: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	err = export(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("failed to export bookmarks: %w", err)
+	}
+
+	fmt.Printf("Bookmarks exported to %s\n", outputPath)
+	return nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func FavoriteCommand() *cli.Command {
+	return &cli.Command{
+		Name: "favorite",
+		Usage: "Mark a bookmark as a favorite\n\n" +
+			"Example:\n" +
+			"  goku favorite --id 123",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "id", Required: true},
+		},
+		Action: func(c *cli.Context) error {
+			return setFavorite(c, true)
+		},
+	}
+}
+
+func UnfavoriteCommand() *cli.Command {
+	return &cli.Command{
+		Name: "unfavorite",
+		Usage: "Unmark a favorite bookmark\n\n" +
+			"Example:\n" +
+			"  goku unfavorite --id 123",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "id", Required: true},
+		},
+		Action: func(c *cli.Context) error {
+			return setFavorite(c, false)
+		},
+	}
+}
+
+func setFavorite(c *cli.Context, favorite bool) error {
+	bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+	if err := bookmarkService.SetFavorite(c.Context, c.Int64("id"), favorite); err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	if favorite {
+		fmt.Println("Bookmark marked as a favorite")
+	} else {
+		fmt.Println("Bookmark is no longer a favorite")
+	}
+	return nil
+}
@@ -2,7 +2,11 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/fallrising/goku-cli/internal/bookmarks"
 	"github.com/fallrising/goku-cli/internal/fetcher"
 	"github.com/fallrising/goku-cli/pkg/models"
@@ -16,8 +20,11 @@ func FetchCommand() *cli.Command {
 			"Examples:\n" +
 			"  goku fetch --id 123\n" +
 			"  goku fetch --all\n" +
-			"  goku fetch --all --limit 20 --skip-internal",
-		Flags: []cli.Flag{
+			"  goku fetch --all --limit 20 --skip-internal\n" +
+			"  goku fetch --all --skip-with-metadata\n" +
+			"  goku fetch --all --fail-fast\n" +
+			"  goku fetch --all --if-changed",
+		Flags: append([]cli.Flag{
 			&cli.IntFlag{
 				Name:  "id",
 				Usage: "Fetch metadata for a specific bookmark ID",
@@ -35,29 +42,71 @@ func FetchCommand() *cli.Command {
 				Name:  "skip-internal",
 				Usage: "Skip URLs with internal IP addresses",
 			},
-		},
+			&cli.BoolFlag{
+				Name:  "skip-with-metadata",
+				Usage: "Skip bookmarks that already have a title and description",
+			},
+			&cli.BoolFlag{
+				Name:  "fail-fast",
+				Usage: "With --all, stop at the first bookmark that fails and return its error",
+			},
+			&cli.BoolFlag{
+				Name:  "if-changed",
+				Usage: "Send the ETag/Last-Modified from the previous fetch and skip pages the server reports unchanged",
+			},
+			waybackFlag(),
+			skipNonHTMLFlag(),
+		}, fetchFlags()...),
 		Action: func(c *cli.Context) error {
 			id := c.Int("id")
 			all := c.Bool("all")
 			limit := c.Int("limit")
-			skipInternal := c.Bool("skip-internal")
+			selection := fetchSelection{
+				skipInternal:     c.Bool("skip-internal"),
+				skipWithMetadata: c.Bool("skip-with-metadata"),
+				ifChanged:        c.Bool("if-changed"),
+			}
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
 
 			if !all && id == 0 {
 				return fmt.Errorf("please specify either --all or --id")
 			}
+			bookmarkService.SetFetchConfig(fetchConfigFromFlags(c))
+			bookmarkService.SetWaybackFallback(c.Bool("wayback"))
 
 			ctx := context.WithValue(context.Background(), "fetchData", true)
 			if all {
-				return fetchAllBookmarks(ctx, bookmarkService, limit, skipInternal)
+				return fetchAllBookmarks(ctx, bookmarkService, limit, selection, c.Bool("fail-fast"))
 			} else {
-				return fetchSingleBookmark(ctx, bookmarkService, int64(id), skipInternal)
+				return fetchSingleBookmark(ctx, bookmarkService, int64(id), selection)
 			}
 		},
 	}
 }
 
-func fetchAllBookmarks(ctx context.Context, bookmarkService *bookmarks.BookmarkService, limit int, skipInternal bool) error {
+// fetchSelection decides which bookmarks a fetch skips. With ifChanged, pages are
+// also skipped when the server reports them unchanged since the previous fetch.
+type fetchSelection struct {
+	skipInternal     bool
+	skipWithMetadata bool
+	ifChanged        bool
+}
+
+// skipReason returns why bookmark should not be fetched, or "" to fetch it.
+func (s fetchSelection) skipReason(bookmark *models.Bookmark) string {
+	switch {
+	case s.skipWithMetadata && bookmark.Title != "" && bookmark.Description != "" &&
+		!strings.HasPrefix(bookmark.Description, "Metadata fetch failed:"):
+		return "already has metadata"
+	case s.skipInternal && fetcher.ValidateIfInternalIP(bookmark.URL):
+		return "internal URL"
+	}
+	return ""
+}
+
+// fetchAllBookmarks refreshes every bookmark's metadata. Failures are reported and
+// skipped unless failFast is set, in which case the first one is returned.
+func fetchAllBookmarks(ctx context.Context, bookmarkService *bookmarks.BookmarkService, limit int, selection fetchSelection, failFast bool) error {
 	offset := 0
 	for {
 		listBookmarks, err := bookmarkService.ListBookmarks(ctx, limit, offset)
@@ -70,7 +119,10 @@ func fetchAllBookmarks(ctx context.Context, bookmarkService *bookmarks.BookmarkS
 		}
 
 		for _, bookmark := range listBookmarks {
-			processBookmark(ctx, bookmarkService, bookmark, skipInternal)
+			err := processBookmark(ctx, bookmarkService, bookmark, selection)
+			if err != nil && failFast {
+				return fmt.Errorf("stopped at bookmark %d (%s): %w", bookmark.ID, bookmark.URL, err)
+			}
 		}
 
 		offset += len(listBookmarks)
@@ -81,24 +133,105 @@ func fetchAllBookmarks(ctx context.Context, bookmarkService *bookmarks.BookmarkS
 	return nil
 }
 
-func fetchSingleBookmark(ctx context.Context, bookmarkService *bookmarks.BookmarkService, id int64, skipInternal bool) error {
+func fetchSingleBookmark(ctx context.Context, bookmarkService *bookmarks.BookmarkService, id int64, selection fetchSelection) error {
 	bookmark, err := bookmarkService.GetBookmark(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get bookmark: %w", err)
 	}
-	processBookmark(ctx, bookmarkService, bookmark, skipInternal)
+	processBookmark(ctx, bookmarkService, bookmark, selection)
 	return nil
 }
 
-func processBookmark(ctx context.Context, bookmarkService *bookmarks.BookmarkService, bookmark *models.Bookmark, skipInternal bool) {
-	if skipInternal && fetcher.ValidateIfInternalIP(bookmark.URL) {
-		fmt.Printf("Skipping internal URL: %s\n", bookmark.URL)
-		return
+// processBookmark refreshes a single bookmark unless selection skips it. Errors are
+// printed and also returned for --fail-fast.
+func processBookmark(ctx context.Context, bookmarkService *bookmarks.BookmarkService, bookmark *models.Bookmark, selection fetchSelection) error {
+	if reason := selection.skipReason(bookmark); reason != "" {
+		fmt.Printf("Skipping %s (%s)\n", bookmark.URL, reason)
+		return nil
+	}
+	changed := true
+	var err error
+	if selection.ifChanged {
+		changed, err = bookmarkService.RefreshMetadataIfChanged(ctx, bookmark)
+	} else {
+		err = bookmarkService.RefreshMetadata(ctx, bookmark)
+	}
+	if errors.Is(err, bookmarks.ErrPageGone) {
+		fmt.Printf("Gone: %s (%v)\n", bookmark.URL, err)
+		return err
 	}
-	err := bookmarkService.UpdateBookmark(ctx, bookmark)
 	if err != nil {
 		fmt.Printf("Error updating bookmark %s: %v\n", bookmark.URL, err)
-	} else {
-		fmt.Printf("Updated metadata for %s\n", bookmark.URL)
+		return err
 	}
+	if !changed {
+		fmt.Printf("Skipping %s (not modified)\n", bookmark.URL)
+		return nil
+	}
+	fmt.Printf("Updated metadata for %s\n", bookmark.URL)
+	return nil
+}
+
+// waybackFlag returns the flag that lets commands which fetch metadata fall back to
+// the Wayback Machine.
+func waybackFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "wayback",
+		Usage: "Read metadata from the Wayback Machine for pages that are unreachable or gone, tagging them 'archived'",
+	}
+}
+
+// skipNonHTMLFlag is shared by the commands that read page metadata.
+func skipNonHTMLFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "skip-non-html",
+		Usage: "Check each URL's type with a HEAD request first and name PDFs, images and other non-HTML files after their URL instead of downloading them",
+	}
+}
+
+// fetchFlags returns the flags that tune requests for commands that fetch URLs.
+func fetchFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "max-retries",
+			Usage: "Number of times to retry a fetch that failed on a network error or a 5xx response",
+			Value: 0,
+		},
+		&cli.DurationFlag{
+			Name:  "retry-base-delay",
+			Usage: "Delay before the first retry; doubles on each further retry",
+			Value: 500 * time.Millisecond,
+		},
+		&cli.IntFlag{
+			Name:  "max-redirects",
+			Usage: "Number of redirects to follow before giving up on a URL",
+			Value: 10,
+		},
+		&cli.BoolFlag{
+			Name:  "respect-robots",
+			Usage: "Skip pages disallowed by robots.txt and wait out each site's Crawl-delay",
+		},
+		&cli.BoolFlag{
+			Name:  "insecure",
+			Usage: "Accept any TLS certificate, e.g. self-signed intranet ones. UNSAFE: exposes fetches to interception; only use on networks you trust",
+		},
+		&cli.BoolFlag{
+			Name:  "allow-internal",
+			Usage: "Fetch URLs on loopback and private IP addresses, e.g. self-hosted services on your home network",
+		},
+	}
+}
+
+// fetchConfigFromFlags builds a FetchConfig from the flags added by fetchFlags and,
+// where the command has it, skipNonHTMLFlag.
+func fetchConfigFromFlags(c *cli.Context) fetcher.FetchConfig {
+	config := fetcher.DefaultFetchConfig()
+	config.Retry.MaxAttempts = c.Int("max-retries") + 1
+	config.Retry.BaseDelay = c.Duration("retry-base-delay")
+	config.MaxRedirects = c.Int("max-redirects")
+	config.RespectRobots = c.Bool("respect-robots")
+	config.InsecureSkipVerify = c.Bool("insecure")
+	config.AllowInternalIPs = c.Bool("allow-internal")
+	config.SkipNonHTML = c.Bool("skip-non-html")
+	return config
 }
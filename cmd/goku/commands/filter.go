@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+// filterFlags returns the flags shared by commands that narrow down bookmarks.
+func filterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{Name: "tag", Usage: "Only include bookmarks with this tag (repeatable)"},
+		&cli.StringFlag{Name: "tag-mode", Value: "all", Usage: "Whether bookmarks need all of the --tag tags or any one of them: all or any"},
+		&cli.StringSliceFlag{Name: "not-tag", Usage: "Exclude bookmarks with this tag (repeatable)"},
+		&cli.StringFlag{Name: "host", Usage: "Only include bookmarks on this host"},
+		&cli.StringFlag{Name: "kind", Usage: "Only include bookmarks of this kind (page, video, document, repo, image)"},
+		&cli.StringFlag{Name: "lang", Usage: "Only include bookmarks in this language, e.g. en (also matches en-US)"},
+		&cli.StringFlag{Name: "updated-since", Usage: "Only include bookmarks updated on or after this date (YYYY-MM-DD or RFC 3339)"},
+		&cli.BoolFlag{Name: "favorites", Usage: "Only include bookmarks marked as favorites"},
+		&cli.BoolFlag{Name: "include-trashed", Usage: "Also include bookmarks in the trash"},
+		&cli.BoolFlag{Name: "only-trashed", Usage: "Only include bookmarks in the trash"},
+	}
+}
+
+// sortFlag is shared by the commands that list bookmarks in pages.
+func sortFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "sort",
+		Usage: "Order results by id, created, updated, title, url or visits, optionally followed by :asc or :desc, e.g. visits:desc",
+	}
+}
+
+// bookmarkFilterFromFlags builds a filter from the flags returned by filterFlags and,
+// where the command has it, sortFlag.
+func bookmarkFilterFromFlags(c *cli.Context) (models.BookmarkFilter, error) {
+	filter := models.BookmarkFilter{
+		Tags:        c.StringSlice("tag"),
+		ExcludeTags: c.StringSlice("not-tag"),
+		Host:        c.String("host"),
+		Kind:        c.String("kind"),
+		Language:    c.String("lang"),
+		Favorites:   c.Bool("favorites"),
+	}
+
+	switch mode := c.String("tag-mode"); mode {
+	case "", "all":
+	case "any":
+		filter.AnyTags = true
+	default:
+		return filter, fmt.Errorf("invalid --tag-mode %q, want all or any", mode)
+	}
+
+	if c.Bool("include-trashed") && c.Bool("only-trashed") {
+		return filter, fmt.Errorf("--include-trashed and --only-trashed cannot be combined")
+	}
+	filter.IncludeTrashed = c.Bool("include-trashed")
+	filter.OnlyTrashed = c.Bool("only-trashed")
+
+	if value := c.String("updated-since"); value != "" {
+		updatedSince, err := parseFilterTime(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --updated-since: %w", err)
+		}
+		filter.UpdatedSince = updatedSince
+	}
+
+	if value := c.String("sort"); value != "" {
+		sort, desc, err := models.ParseSort(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --sort: %w", err)
+		}
+		filter.Sort, filter.SortDesc = sort, desc
+	}
+
+	return filter, nil
+}
+
+// parseFilterTime accepts a date (taken as local midnight) or an RFC 3339 timestamp.
+func parseFilterTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+// formatFlag lets list, search, get and stats print their output as JSON instead of text.
+func formatFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "format",
+		Value: "text",
+		Usage: "Output format: text or json",
+	}
+}
+
+// jsonFromFlags reports whether --format asks for JSON. JSON output cannot be
+// combined with --template, where the command has it.
+func jsonFromFlags(c *cli.Context) (bool, error) {
+	switch format := c.String("format"); format {
+	case "text":
+		return false, nil
+	case "json":
+		if c.String("template") != "" {
+			return false, fmt.Errorf("--template cannot be combined with --format json")
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// printJSON writes v to stdout as a single line of JSON.
+func printJSON(v interface{}) error {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// nonNilBookmarks returns bookmarks, or an empty slice if it is nil, so that an empty
+// page is printed as [] rather than null.
+func nonNilBookmarks(bookmarks []*models.Bookmark) []*models.Bookmark {
+	if bookmarks == nil {
+		return []*models.Bookmark{}
+	}
+	return bookmarks
+}
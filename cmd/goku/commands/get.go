@@ -4,26 +4,75 @@ import (
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
+	"strings"
 )
 
 func GetCommand() *cli.Command {
 	return &cli.Command{
 		Name: "get",
 		Usage: "Get a bookmark by ID\n\n" +
-			"Example:\n" +
-			"  goku get --id 123",
+			"Examples:\n" +
+			"  goku get --id 123\n" +
+			"  goku get --id 123 --format json",
 		Flags: []cli.Flag{
 			&cli.Int64Flag{Name: "id", Required: true},
+			formatFlag(),
 		},
 		Action: func(c *cli.Context) error {
+			asJSON, err := jsonFromFlags(c)
+			if err != nil {
+				return err
+			}
+
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
-			bookmark, err := bookmarkService.GetBookmark(context.Background(), c.Int64("id"))
+			id := c.Int64("id")
+			bookmark, err := bookmarkService.GetBookmark(context.Background(), id)
 			if err != nil {
-				return fmt.Errorf("failed to get bookmark: %w", err)
+				return fmt.Errorf("failed to get bookmark %d: %w", id, err)
 			}
-			fmt.Printf("Bookmark: %+v\n", bookmark)
+
+			if asJSON {
+				return printJSON(bookmark)
+			}
+			printBookmarkDetails(bookmark)
 			return nil
 		},
 	}
 }
+
+// printBookmarkDetails prints every field of bookmark, one per line. Optional fields
+// are left out when they are empty.
+func printBookmarkDetails(b *models.Bookmark) {
+	const timeLayout = "2006-01-02 15:04:05"
+	field := func(name, value string) {
+		fmt.Printf("%-14s %s\n", name+":", value)
+	}
+	optional := func(name, value string) {
+		if value != "" {
+			field(name, value)
+		}
+	}
+
+	field("ID", fmt.Sprint(b.ID))
+	field("URL", b.URL)
+	field("Title", b.Title)
+	field("Description", b.Description)
+	field("Tags", strings.Join(b.Tags, ", "))
+	optional("Kind", b.Kind)
+	optional("Language", b.Language)
+	optional("Canonical URL", b.CanonicalURL)
+	optional("Feed URL", b.FeedURL)
+	if b.Favorite {
+		field("Favorite", "yes")
+	}
+	if b.LastVisited != nil {
+		field("Visits", fmt.Sprintf("%d, last %s", b.VisitCount, b.LastVisited.Local().Format(timeLayout)))
+	}
+	field("Created", b.CreatedAt.Local().Format(timeLayout))
+	field("Updated", b.UpdatedAt.Local().Format(timeLayout))
+	if b.DeletedAt != nil {
+		field("Deleted", b.DeletedAt.Local().Format(timeLayout))
+	}
+}
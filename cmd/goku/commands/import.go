@@ -6,22 +6,26 @@ import (
 	"github.com/fallrising/goku-cli/internal/bookmarks"
 	"github.com/urfave/cli/v2"
 	"os"
+	"os/signal"
 	"strings"
 )
 
 func ImportCommand() *cli.Command {
 	return &cli.Command{
 		Name: "import",
-		Usage: "Import bookmarks from HTML, JSON, or plain text URL list\n\n" +
+		Usage: "Import bookmarks from HTML, JSON, CSV, or plain text URL list, or a .zip/.tar.gz of them\n\n" +
 			"Examples:\n" +
 			"  goku import --file bookmarks.html\n" +
 			"  goku import -f bookmarks.json --workers 10\n" +
-			"  goku import --file bookmarks.txt",
-		Flags: []cli.Flag{
+			"  goku import --file bookmarks.html --folders-as-tags\n" +
+			"  goku import --file bookmarks.txt\n" +
+			"  goku import --file bookmarks.csv\n" +
+			"  goku import --file exports.zip",
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:     "file",
 				Aliases:  []string{"f"},
-				Usage:    "Input file path (.html, .json, or .txt)",
+				Usage:    "Input file path (.html, .json, .csv, .txt, .zip, or .tar.gz)",
 				Required: true,
 			},
 			&cli.IntFlag{
@@ -36,12 +40,20 @@ func ImportCommand() *cli.Command {
 				Usage:   "Enable fetching additional data for each bookmark",
 				Value:   false, // Disabled by default
 			},
-		},
+			&cli.BoolFlag{
+				Name:  "folders-as-tags",
+				Usage: "Tag each bookmark with the names of the folders it is filed in (HTML and JSON files)",
+			},
+			waybackFlag(),
+			skipNonHTMLFlag(),
+		}, fetchFlags()...),
 		Action: func(c *cli.Context) error {
 			filePath := c.String("file")
 			numWorkers := c.Int("workers")
 			fetchData := c.Bool("fetch")
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			bookmarkService.SetFetchConfig(fetchConfigFromFlags(c))
+			bookmarkService.SetWaybackFallback(c.Bool("wayback"))
 
 			// Open the file
 			file, err := openFile(filePath)
@@ -50,27 +62,44 @@ func ImportCommand() *cli.Command {
 			}
 			defer file.Close()
 
+			// Ctrl-C cancels the import, aborting fetches that are in flight
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
 			// Create a context with the import options
-			ctx := context.WithValue(context.Background(), "numWorkers", numWorkers)
+			ctx = context.WithValue(ctx, "numWorkers", numWorkers)
 			ctx = context.WithValue(ctx, "fetchData", fetchData)
+			ctx = context.WithValue(ctx, "foldersAsTags", c.Bool("folders-as-tags"))
 
 			// Determine import type based on file extension
-			var recordsCreated int
+			var result *bookmarks.ImportResult
 			if isJSON(filePath) {
-				recordsCreated, err = bookmarkService.ImportFromJSON(ctx, file)
+				result, err = bookmarkService.ImportFromJSON(ctx, file)
 			} else if isHTML(filePath) {
-				recordsCreated, err = bookmarkService.ImportFromHTML(ctx, file)
+				result, err = bookmarkService.ImportFromHTML(ctx, file)
 			} else if isText(filePath) {
-				recordsCreated, err = bookmarkService.ImportFromText(ctx, file)
+				result, err = bookmarkService.ImportFromText(ctx, file)
+			} else if isCSV(filePath) {
+				result, err = bookmarkService.ImportFromCSV(ctx, file)
+			} else if isZip(filePath) {
+				var info os.FileInfo
+				if info, err = file.Stat(); err != nil {
+					return fmt.Errorf("failed to stat file: %w", err)
+				}
+				result, err = bookmarkService.ImportFromZip(ctx, file, info.Size())
+			} else if isTarGz(filePath) {
+				result, err = bookmarkService.ImportFromTarGz(ctx, file)
 			} else {
 				return fmt.Errorf("unsupported file format: %s", filePath)
 			}
 
+			if result != nil {
+				printImportSummary(result)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to import bookmarks: %w", err)
 			}
 
-			fmt.Printf("Import completed. %d bookmarks were successfully imported.\n", recordsCreated)
 			if fetchData {
 				fmt.Println("Additional data was fetched for each bookmark.")
 			}
@@ -79,6 +108,26 @@ func ImportCommand() *cli.Command {
 	}
 }
 
+// printImportSummary prints how many bookmarks were imported and why the others failed.
+func printImportSummary(result *bookmarks.ImportResult) {
+	fmt.Printf("Import completed. %d of %d bookmarks were successfully imported.\n", result.Created, result.Total)
+	if len(result.SkippedFiles) > 0 {
+		fmt.Printf("  skipped %d non-bookmark files: %s\n", len(result.SkippedFiles), strings.Join(result.SkippedFiles, ", "))
+	}
+	if result.FetchFailed > 0 {
+		fmt.Printf("  fetch-failed: %d (imported without metadata)\n", result.FetchFailed)
+	}
+	if result.Failed() == 0 {
+		return
+	}
+	fmt.Printf("  duplicate:    %d\n", result.Duplicates)
+	fmt.Printf("  invalid-url:  %d\n", result.InvalidURLs)
+	fmt.Printf("  db-error:     %d\n", result.DBErrors)
+	if len(result.Errors) > 0 {
+		fmt.Println("See goku.log for details of each error.")
+	}
+}
+
 // openFile opens the file and returns an error if it fails.
 func openFile(filePath string) (*os.File, error) {
 	file, err := os.Open(filePath)
@@ -102,3 +151,18 @@ func isHTML(filePath string) bool {
 func isText(filePath string) bool {
 	return strings.HasSuffix(strings.ToLower(filePath), ".txt")
 }
+
+// isCSV checks if the file is a CSV file based on the file extension.
+func isCSV(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), ".csv")
+}
+
+// isZip checks if the file is a zip archive based on the file extension.
+func isZip(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), ".zip")
+}
+
+// isTarGz checks if the file is a gzipped tar archive based on the file extension.
+func isTarGz(filePath string) bool {
+	return strings.HasSuffix(strings.ToLower(filePath), ".tar.gz") || strings.HasSuffix(strings.ToLower(filePath), ".tgz")
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
+	"net/url"
+	"strings"
 )
 
 func ListCommand() *cli.Command {
@@ -13,29 +16,96 @@ func ListCommand() *cli.Command {
 		Usage: "List all bookmarks with pagination\n\n" +
 			"Examples:\n" +
 			"  goku list\n" +
-			"  goku list --limit 20 --offset 40",
-		Flags: []cli.Flag{
+			"  goku list --limit 20 --offset 40\n" +
+			"  goku list --summary\n" +
+			"  goku list --tag go --not-tag tutorial\n" +
+			"  goku list --sort created:desc\n" +
+			"  goku list --template '{{.ID}} {{.URL}}'\n" +
+			"  goku list --tag go --format json",
+		Flags: append([]cli.Flag{
 			&cli.IntFlag{Name: "limit", Value: 10, Usage: "Number of bookmarks to display per page"},
 			&cli.IntFlag{Name: "offset", Value: 0, Usage: "Offset to start listing bookmarks from"},
-		},
+			&cli.BoolFlag{Name: "summary", Usage: "Print distinct hostname and tag counts for the listed bookmarks"},
+			templateFlag(),
+			formatFlag(),
+			sortFlag(),
+		}, filterFlags()...),
 		Action: func(c *cli.Context) error {
 			limit := c.Int("limit")
 			offset := c.Int("offset")
 
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
-			listBookmarks, err := bookmarkService.ListBookmarks(context.Background(), limit, offset)
+			filter, err := bookmarkFilterFromFlags(c)
+			if err != nil {
+				return err
+			}
+			rowTemplate, err := rowTemplateFromFlags(c)
+			if err != nil {
+				return err
+			}
+			asJSON, err := jsonFromFlags(c)
+			if err != nil {
+				return err
+			}
+			if asJSON && c.Bool("summary") {
+				return fmt.Errorf("--summary cannot be combined with --format json")
+			}
+			listBookmarks, total, err := bookmarkService.ListBookmarksWithCount(context.Background(), filter, limit, offset)
 			if err != nil {
 				return fmt.Errorf("failed to list listBookmarks: %w", err)
 			}
-			if len(listBookmarks) == 0 {
+			if asJSON {
+				return printJSON(nonNilBookmarks(listBookmarks))
+			}
+			if rowTemplate != nil {
+				if err := printBookmarksWithTemplate(rowTemplate, listBookmarks); err != nil {
+					return err
+				}
+				if c.Bool("summary") {
+					printListSummary(listBookmarks)
+				}
+				return nil
+			}
+			if total == 0 {
 				fmt.Println("No listBookmarks found.")
 				return nil
 			}
-			fmt.Printf("Displaying %d bookmark(s):\n", len(listBookmarks))
+			if len(listBookmarks) == 0 {
+				fmt.Printf("No bookmarks at offset %d; %d bookmark(s) in total.\n", offset, total)
+				return nil
+			}
+			fmt.Printf("Displaying %s of %d bookmark(s):\n", pageRange(offset, len(listBookmarks)), total)
 			for _, b := range listBookmarks {
-				fmt.Printf("ID: %d, URL: %s, Title: %s, Tags: %v, Description: %v\n", b.ID, b.URL, b.Title, b.Tags, b.Description)
+				fmt.Printf("ID: %d, URL: %s, Title: %s, Tags: %v, Description: %v%s\n", b.ID, b.URL, b.Title, b.Tags, b.Description, trashedSuffix(b))
+			}
+			if c.Bool("summary") {
+				printListSummary(listBookmarks)
 			}
 			return nil
 		},
 	}
 }
+
+// pageRange describes the position of a page of n results starting at offset, e.g.
+// "11-20".
+func pageRange(offset, n int) string {
+	return fmt.Sprintf("%d-%d", offset+1, offset+n)
+}
+
+// printListSummary prints a one-line footer with the number of distinct hostnames and tags in the given bookmarks.
+func printListSummary(listBookmarks []*models.Bookmark) {
+	hostnames := make(map[string]struct{})
+	tags := make(map[string]struct{})
+	for _, b := range listBookmarks {
+		if u, err := url.Parse(b.URL); err == nil && u.Hostname() != "" {
+			hostnames[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")] = struct{}{}
+		}
+		for _, tag := range b.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag != "" {
+				tags[tag] = struct{}{}
+			}
+		}
+	}
+	fmt.Printf("Summary: %d bookmark(s), %d distinct hostname(s), %d distinct tag(s)\n", len(listBookmarks), len(hostnames), len(tags))
+}
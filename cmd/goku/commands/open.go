@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+	"os/exec"
+	"runtime"
+)
+
+func OpenCommand() *cli.Command {
+	return &cli.Command{
+		Name: "open",
+		Usage: "Open a bookmark in the browser and record the visit\n\n" +
+			"Examples:\n" +
+			"  goku open --id 123\n" +
+			"  goku open --id 123 --print | xargs firefox",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "id", Required: true},
+			&cli.BoolFlag{
+				Name:  "print",
+				Usage: "Print the URL instead of opening it, e.g. to pass it to another command",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			id := c.Int64("id")
+			bookmark, err := bookmarkService.GetBookmark(context.Background(), id)
+			if err != nil {
+				return fmt.Errorf("failed to get bookmark %d: %w", id, err)
+			}
+
+			if c.Bool("print") {
+				fmt.Println(bookmark.URL)
+			} else if err := openURL(bookmark.URL); err != nil {
+				return fmt.Errorf("failed to open %s: %w", bookmark.URL, err)
+			}
+
+			if err := bookmarkService.RecordVisit(context.Background(), id); err != nil {
+				return fmt.Errorf("failed to record visit: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// openURL opens url with the platform's handler for it, usually the default browser.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Run()
+}
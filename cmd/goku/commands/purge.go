@@ -3,23 +3,43 @@ package commands
 import (
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
 )
 
+// previewSampleSize is how many bookmarks a --dry-run lists before summarizing the rest.
+const previewSampleSize = 10
+
 func PurgeCommand() *cli.Command {
 	return &cli.Command{
 		Name: "purge",
-		Usage: "Delete all bookmarks from the database\n\n" +
+		Usage: "Delete all bookmarks from the database, including the trash\n\n" +
 			"Examples:\n" +
 			"  goku purge\n" +
-			"  goku purge --force",
+			"  goku purge --force\n" +
+			"  goku purge --dry-run",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "force",
 				Usage: "Force purge without confirmation",
 			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be deleted without deleting anything",
+			},
 		},
 		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+			if c.Bool("dry-run") {
+				count, sample, err := bookmarkService.PreviewPurge(c.Context, previewSampleSize)
+				if err != nil {
+					return fmt.Errorf("failed to preview purge: %w", err)
+				}
+				printRemovalPreview(count, sample)
+				return nil
+			}
+
 			if !c.Bool("force") {
 				fmt.Print("Are you sure you want to purge all bookmarks? This action cannot be undone. (y/N): ")
 				var response string
@@ -30,7 +50,6 @@ func PurgeCommand() *cli.Command {
 				}
 			}
 
-			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
 			err := bookmarkService.PurgeBookmarks(c.Context)
 			if err != nil {
 				return fmt.Errorf("failed to purge bookmarks: %w", err)
@@ -41,3 +60,15 @@ func PurgeCommand() *cli.Command {
 		},
 	}
 }
+
+// printRemovalPreview prints the outcome of a --dry-run: the number of bookmarks that
+// would be removed and a sample of them.
+func printRemovalPreview(count int, sample []*models.Bookmark) {
+	fmt.Printf("Dry run: %d bookmark(s) would be deleted.\n", count)
+	for _, bookmark := range sample {
+		fmt.Printf("  %d: %s (%s)\n", bookmark.ID, bookmark.Title, bookmark.URL)
+	}
+	if remaining := count - len(sample); remaining > 0 {
+		fmt.Printf("  ... and %d more\n", remaining)
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func RestoreCommand() *cli.Command {
+	return &cli.Command{
+		Name: "restore",
+		Usage: "Restore a deleted bookmark from the trash, or the whole database from a backup\n\n" +
+			"Examples:\n" +
+			"  goku restore --id 123\n" +
+			"  goku restore --input goku-backup.db --force",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "id", Usage: "ID of the deleted bookmark to take out of the trash"},
+			&cli.StringFlag{
+				Name:    "input",
+				Aliases: []string{"i"},
+				Usage:   "Backup file made by 'goku backup' to replace every bookmark with",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Restore a backup without confirmation",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+			switch {
+			case c.IsSet("id") && c.IsSet("input"):
+				return fmt.Errorf("--id and --input cannot be combined")
+			case c.IsSet("input"):
+				return restoreBackup(c, bookmarkService, c.String("input"))
+			case !c.IsSet("id"):
+				return fmt.Errorf("either --id or --input is required")
+			}
+
+			err := bookmarkService.RestoreBookmark(c.Context, c.Int64("id"))
+			if err != nil {
+				return fmt.Errorf("failed to restore bookmark: %w", err)
+			}
+			fmt.Println("Bookmark restored successfully")
+			return nil
+		},
+	}
+}
+
+// restoreBackup replaces the database contents with the backup at inputPath, asking
+// for confirmation unless --force is set.
+func restoreBackup(c *cli.Context, bookmarkService *bookmarks.BookmarkService, inputPath string) error {
+	if !c.Bool("force") {
+		fmt.Printf("Are you sure you want to replace all bookmarks with the contents of %s? This action cannot be undone. (y/N): ", inputPath)
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Println("Restore cancelled.")
+			return nil
+		}
+	}
+
+	if err := bookmarkService.RestoreBackup(c.Context, inputPath); err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	fmt.Printf("Database restored from %s\n", inputPath)
+	return nil
+}
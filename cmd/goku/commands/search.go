@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
 )
 
@@ -14,29 +15,69 @@ func SearchCommand() *cli.Command {
 			"Examples:\n" +
 			"  goku search --query \"example\"\n" +
 			"  goku search -q \"tag:programming\" --limit 20\n" +
-			"  goku search --query \"important\" --offset 10 --limit 5",
-		Flags: []cli.Flag{
+			"  goku search --query \"important\" --offset 10 --limit 5\n" +
+			"  goku search -q \"generics\" --tag go --not-tag tutorial\n" +
+			"  goku search -q \"kubernets netwrking\" --fuzzy\n" +
+			"  goku search -q \"generics\" --sort updated:desc\n" +
+			"  goku search -q \"generics\" --template '{{.URL}} {{join .Tags \",\"}}'\n" +
+			"  goku search -q \"generics\" --format json",
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{Name: "query", Aliases: []string{"q"}, Required: true, Usage: "Search query"},
 			&cli.IntFlag{Name: "limit", Value: 10, Usage: "Number of bookmarks to display per page"},
 			&cli.IntFlag{Name: "offset", Value: 0, Usage: "Offset to start search results from"},
-		},
+			&cli.BoolFlag{Name: "fuzzy", Usage: "Rank bookmarks by how closely their title or URL resembles the query, tolerating typos"},
+			templateFlag(),
+			formatFlag(),
+			sortFlag(),
+		}, filterFlags()...),
 		Action: func(c *cli.Context) error {
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
 			query := c.String("query")
 			limit := c.Int("limit")
 			offset := c.Int("offset")
 
-			searchBookmarks, err := bookmarkService.SearchBookmarks(context.Background(), query, limit, offset)
+			filter, err := bookmarkFilterFromFlags(c)
+			if err != nil {
+				return err
+			}
+			rowTemplate, err := rowTemplateFromFlags(c)
+			if err != nil {
+				return err
+			}
+			asJSON, err := jsonFromFlags(c)
+			if err != nil {
+				return err
+			}
+			var searchBookmarks []*models.Bookmark
+			var total int
+			if c.Bool("fuzzy") && filter.Sort != "" {
+				return fmt.Errorf("--sort cannot be combined with --fuzzy, which orders by similarity")
+			}
+			if c.Bool("fuzzy") {
+				searchBookmarks, total, err = bookmarkService.FuzzySearchBookmarks(context.Background(), query, filter, limit, offset)
+			} else {
+				searchBookmarks, total, err = bookmarkService.SearchBookmarksWithCount(context.Background(), query, filter, limit, offset)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to search bookmarks: %w", err)
 			}
-			if len(searchBookmarks) == 0 {
+			if asJSON {
+				return printJSON(nonNilBookmarks(searchBookmarks))
+			}
+			if rowTemplate != nil {
+				return printBookmarksWithTemplate(rowTemplate, searchBookmarks)
+			}
+			if total == 0 {
 				fmt.Println("No bookmarks found matching the query.")
 				return nil
 			}
-			fmt.Printf("Found %d bookmark(s):\n", len(searchBookmarks))
+			if len(searchBookmarks) == 0 {
+				fmt.Printf("No bookmarks at offset %d; %d bookmark(s) match in total.\n", offset, total)
+				return nil
+			}
+			fmt.Printf("Found %d bookmark(s), showing %s:\n", total, pageRange(offset, len(searchBookmarks)))
 			for _, b := range searchBookmarks {
-				fmt.Printf("ID: %d, URL: %s, Title: %s, Tags: %v, Description: %v\n", b.ID, b.URL, b.Title, b.Tags, b.Description)
+				fmt.Printf("ID: %d, URL: %s, Title: %s, Tags: %v, Description: %v%s\n", b.ID, b.URL, b.Title, b.Tags, b.Description, trashedSuffix(b))
 			}
 			return nil
 		},
@@ -1,10 +1,13 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/urfave/cli/v2"
+	"os"
 	"sort"
 )
 
@@ -12,52 +15,111 @@ func StatsCommand() *cli.Command {
 	return &cli.Command{
 		Name: "stats",
 		Usage: "Display bookmark statistics\n\n" +
-			"Example:\n" +
-			"  goku stats",
+			"Examples:\n" +
+			"  goku stats\n" +
+			"  goku stats --days 30 --top 10 --format json\n" +
+			"  goku stats export --days 365 --output growth.csv",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Export daily bookmark counts as CSV (date,count)",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "days", Value: 30, Usage: "Number of days to include"},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Output file path (default: stdout)"},
+				},
+				Action: func(c *cli.Context) error {
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					outputPath := c.String("output")
+					if outputPath == "" {
+						if err := bookmarkService.ExportGrowthCSV(context.Background(), os.Stdout, c.Int("days")); err != nil {
+							return fmt.Errorf("failed to export statistics: %w", err)
+						}
+						return nil
+					}
+
+					// Build the CSV in memory so a failed export doesn't leave a partial file behind
+					var buf bytes.Buffer
+					if err := bookmarkService.ExportGrowthCSV(context.Background(), &buf, c.Int("days")); err != nil {
+						return fmt.Errorf("failed to export statistics: %w", err)
+					}
+					if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+						return fmt.Errorf("failed to write to file: %w", err)
+					}
+					fmt.Printf("Statistics exported to %s\n", outputPath)
+					return nil
+				},
+			},
+		},
+		Flags: statisticsFlags(),
 		Action: func(c *cli.Context) error {
+			asJSON, err := jsonFromFlags(c)
+			if err != nil {
+				return err
+			}
+
 			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
-			stats, err := bookmarkService.GetStatistics(context.Background())
+			days, top := c.Int("days"), c.Int("top")
+			stats, err := bookmarkService.GetStatistics(context.Background(), days, top)
 			if err != nil {
 				return fmt.Errorf("failed to get statistics: %w", err)
 			}
 
-			fmt.Println("Bookmark Statistics:")
-			fmt.Println("--------------------")
-
-			fmt.Println("\nTop 3 Hostnames:")
-			for _, hc := range stats.TopHostnames {
-				fmt.Printf("%s: %d\n", hc.Hostname, hc.Count)
+			if asJSON {
+				return printJSON(stats)
 			}
 
-			fmt.Println("\nBookmarks by Accessibility:")
-			fmt.Printf("Accessible: %d\n", stats.AccessibilityCounts["accessible"])
-			fmt.Printf("Inaccessible: %d\n", stats.AccessibilityCounts["inaccessible"])
+			printStatistics(stats, days, top)
+			return nil
+		},
+	}
+}
 
-			fmt.Println("\nTop 5 Tags:")
-			sortedTags := make([]string, 0, len(stats.TagCounts))
-			for tag := range stats.TagCounts {
-				sortedTags = append(sortedTags, tag)
-			}
-			sort.Slice(sortedTags, func(i, j int) bool {
-				return stats.TagCounts[sortedTags[i]] > stats.TagCounts[sortedTags[j]]
-			})
-			for i := 0; i < 5 && i < len(sortedTags); i++ {
-				fmt.Printf("%s: %d\n", sortedTags[i], stats.TagCounts[sortedTags[i]])
-			}
+// statisticsFlags are the report options shared by stats and analytics.
+func statisticsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{Name: "days", Value: 7, Usage: "Number of days of daily creation counts to include"},
+		&cli.IntFlag{Name: "top", Value: 5, Usage: "Number of top hostnames and tags to show"},
+		formatFlag(),
+	}
+}
 
-			fmt.Println("\nLatest 10 Bookmarks:")
-			for _, b := range stats.LatestBookmarks {
-				fmt.Printf("%s - %s\n", b.CreatedAt.Format("2006-01-02"), b.Title)
-			}
+// printStatistics prints the text report of stats and analytics. days and top are the
+// limits stats was read with, for the headings.
+func printStatistics(stats *models.Statistics, days, top int) {
+	fmt.Println("Bookmark Statistics:")
+	fmt.Println("--------------------")
 
-			fmt.Println("\nBookmarks Created in the Last 7 Days:")
-			for day, count := range stats.CreatedLastWeek {
-				fmt.Printf("%s: %d\n", day, count)
-			}
+	fmt.Printf("\nTop %d Hostnames:\n", top)
+	for _, hc := range stats.TopHostnames {
+		fmt.Printf("%s: %d\n", hc.Hostname, hc.Count)
+	}
 
-			fmt.Printf("\nTotal Unique Hostnames: %d\n", len(stats.UniqueHostnames))
+	fmt.Println("\nBookmarks by Accessibility:")
+	fmt.Printf("Accessible: %d\n", stats.AccessibilityCounts["accessible"])
+	fmt.Printf("Inaccessible: %d\n", stats.AccessibilityCounts["inaccessible"])
 
-			return nil
-		},
+	fmt.Printf("\nTop %d Tags:\n", top)
+	sortedTags := make([]string, 0, len(stats.TagCounts))
+	for tag := range stats.TagCounts {
+		sortedTags = append(sortedTags, tag)
 	}
+	sort.Slice(sortedTags, func(i, j int) bool {
+		return stats.TagCounts[sortedTags[i]] > stats.TagCounts[sortedTags[j]]
+	})
+	for i := 0; i < top && i < len(sortedTags); i++ {
+		fmt.Printf("%s: %d\n", sortedTags[i], stats.TagCounts[sortedTags[i]])
+	}
+
+	fmt.Println("\nLatest 10 Bookmarks:")
+	for _, b := range stats.LatestBookmarks {
+		fmt.Printf("%s - %s\n", b.CreatedAt.Format("2006-01-02"), b.Title)
+	}
+
+	fmt.Printf("\nBookmarks Created in the Last %d Days:\n", days)
+	for day, count := range stats.CreatedByDay {
+		fmt.Printf("%s: %d\n", day, count)
+	}
+
+	fmt.Printf("\nTotal Unique Hostnames: %d\n", len(stats.UniqueHostnames))
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/bookmarks"
 	"github.com/urfave/cli/v2"
+	"strings"
 )
 
 func TagsCommand() *cli.Command {
@@ -13,8 +14,34 @@ func TagsCommand() *cli.Command {
 		Usage: "Manage tags for bookmarks\n\n" +
 			"Examples:\n" +
 			"  goku tags list\n" +
-			"  goku tags remove --id 123 --tag oldtag",
+			"  goku tags list --plain | fzf\n" +
+			"  goku tags add --id 123 --tag go --tag reference\n" +
+			"  goku tags remove --id 123 --tag oldtag\n" +
+			"  goku tags rename --from progamming --to programming\n" +
+			"  goku tags merge --from js,javascript --into javascript\n" +
+			"  goku tags prune --dry-run\n" +
+			"  goku tags normalize",
 		Subcommands: []*cli.Command{
+			{
+				Name:  "add",
+				Usage: "Add tags to a bookmark",
+				Flags: []cli.Flag{
+					&cli.Int64Flag{Name: "id", Required: true, Usage: "Bookmark ID"},
+					&cli.StringSliceFlag{Name: "tag", Required: true, Usage: "Tag to add (repeatable or comma-separated)"},
+				},
+				Action: func(c *cli.Context) error {
+					bookmarkID := c.Int64("id")
+					tags := c.StringSlice("tag")
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					err := bookmarkService.AddTagToBookmark(context.Background(), bookmarkID, tags...)
+					if err != nil {
+						return fmt.Errorf("failed to add tags: %w", err)
+					}
+					fmt.Println("Tags added successfully")
+					return nil
+				},
+			},
 			{
 				Name:  "remove",
 				Usage: "Remove a tag from a bookmark",
@@ -35,15 +62,117 @@ func TagsCommand() *cli.Command {
 					return nil
 				},
 			},
+			{
+				Name:  "rename",
+				Usage: "Rename a tag across all bookmarks",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "from", Required: true, Usage: "Tag to rename"},
+					&cli.StringFlag{Name: "to", Required: true, Usage: "New name of the tag"},
+				},
+				Action: func(c *cli.Context) error {
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					renamed, err := bookmarkService.RenameTag(context.Background(), c.String("from"), c.String("to"))
+					if err != nil {
+						return fmt.Errorf("failed to rename tag: %w", err)
+					}
+					fmt.Printf("Renamed '%s' to '%s' on %d bookmark(s)\n", c.String("from"), c.String("to"), renamed)
+					return nil
+				},
+			},
+			{
+				Name:  "merge",
+				Usage: "Replace several tags with a single tag across all bookmarks",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "from", Required: true, Usage: "Tags to merge (comma-separated)"},
+					&cli.StringFlag{Name: "into", Required: true, Usage: "Tag to merge into"},
+				},
+				Action: func(c *cli.Context) error {
+					from := c.StringSlice("from")
+					into := c.String("into")
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					counts, err := bookmarkService.MergeTags(context.Background(), from, into)
+					if err != nil {
+						return fmt.Errorf("failed to merge tags: %w", err)
+					}
+					for _, tag := range from {
+						tag = strings.ToLower(strings.TrimSpace(tag))
+						fmt.Printf(" - %s: %d bookmark(s)\n", tag, counts[tag])
+					}
+					fmt.Printf("Tags merged into '%s' successfully\n", into)
+					return nil
+				},
+			},
+			{
+				Name:  "prune",
+				Usage: "Remove tags that no bookmark uses any more",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "dry-run", Usage: "List the unused tags without removing them"},
+				},
+				Action: func(c *cli.Context) error {
+					dryRun := c.Bool("dry-run")
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					tags, err := bookmarkService.PruneTags(context.Background(), dryRun)
+					if err != nil {
+						return err
+					}
+					if len(tags) == 0 {
+						fmt.Println("No unused tags found.")
+						return nil
+					}
+					for _, tag := range tags {
+						fmt.Println(" -", tag)
+					}
+					if dryRun {
+						fmt.Printf("Dry run: %d unused tag(s) would be removed.\n", len(tags))
+					} else {
+						fmt.Printf("Removed %d unused tag(s).\n", len(tags))
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "normalize",
+				Usage: "Rewrite every bookmark's tags in canonical form: lowercase, trimmed, no empty or repeated tags",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "dry-run", Usage: "Count the bookmarks that would change without changing them"},
+				},
+				Action: func(c *cli.Context) error {
+					dryRun := c.Bool("dry-run")
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					changed, err := bookmarkService.NormalizeTags(context.Background(), dryRun)
+					if err != nil {
+						return err
+					}
+					if dryRun {
+						fmt.Printf("Dry run: tags of %d bookmark(s) would be rewritten.\n", changed)
+					} else {
+						fmt.Printf("Rewrote tags of %d bookmark(s).\n", changed)
+					}
+					return nil
+				},
+			},
 			{
 				Name:  "list",
 				Usage: "List all unique tags",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "plain", Usage: "Print one tag per line with no header, for shell completion and fzf"},
+				},
 				Action: func(c *cli.Context) error {
 					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
 					tags, err := bookmarkService.ListAllTags(context.Background())
 					if err != nil {
 						return fmt.Errorf("failed to list tags: %w", err)
 					}
+					if c.Bool("plain") {
+						for _, tag := range tags {
+							fmt.Println(tag)
+						}
+						return nil
+					}
 					if len(tags) == 0 {
 						fmt.Println("No tags found.")
 						return nil
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+// templateFlag lets list and search print each bookmark with a Go text/template.
+func templateFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "template",
+		Usage: "Print each bookmark with a Go text/template, e.g. '{{.ID}} {{.URL}}' (fields: ID, URL, Title, Description, Tags, Kind, CreatedAt, UpdatedAt)",
+	}
+}
+
+// rowTemplateFromFlags parses the --template flag, returning nil if it wasn't given.
+// A newline is added after each row unless the template ends with one.
+func rowTemplateFromFlags(c *cli.Context) (*template.Template, error) {
+	text := c.String("template")
+	if text == "" {
+		return nil, nil
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	tmpl, err := template.New("row").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printBookmarksWithTemplate writes one templated row per bookmark to stdout.
+func printBookmarksWithTemplate(tmpl *template.Template, bookmarks []*models.Bookmark) error {
+	for _, b := range bookmarks {
+		if err := tmpl.Execute(os.Stdout, b); err != nil {
+			return fmt.Errorf("failed to render bookmark %d: %w", b.ID, err)
+		}
+	}
+	return nil
+}
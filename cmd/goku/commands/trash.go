@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/fallrising/goku-cli/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+func TrashCommand() *cli.Command {
+	return &cli.Command{
+		Name: "trash",
+		Usage: "List or empty deleted bookmarks\n\n" +
+			"Examples:\n" +
+			"  goku trash list\n" +
+			"  goku trash empty --force",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List bookmarks in the trash, most recently deleted first",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "limit", Value: 10, Usage: "Number of bookmarks to display per page"},
+					&cli.IntFlag{Name: "offset", Value: 0, Usage: "Offset to start listing bookmarks from"},
+				},
+				Action: func(c *cli.Context) error {
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					deleted, err := bookmarkService.ListDeletedBookmarks(c.Context, c.Int("limit"), c.Int("offset"))
+					if err != nil {
+						return err
+					}
+					if len(deleted) == 0 {
+						fmt.Println("The trash is empty.")
+						return nil
+					}
+					for _, b := range deleted {
+						fmt.Printf("ID: %d, URL: %s, Title: %s%s\n", b.ID, b.URL, b.Title, trashedSuffix(b))
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "empty",
+				Usage: "Permanently delete the bookmarks in the trash",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "force", Usage: "Empty the trash without confirmation"},
+				},
+				Action: func(c *cli.Context) error {
+					bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+
+					if !c.Bool("force") {
+						fmt.Print("Are you sure you want to empty the trash? This action cannot be undone. (y/N): ")
+						var response string
+						fmt.Scanln(&response)
+						if response != "y" && response != "Y" {
+							fmt.Println("Operation cancelled.")
+							return nil
+						}
+					}
+
+					purged, err := bookmarkService.EmptyTrash(c.Context)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Permanently deleted %d bookmark(s) from the trash.\n", purged)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// trashedSuffix marks a listed bookmark that is in the trash with when it was
+// deleted, e.g. ", Deleted: 2024-05-01 14:30". It is empty for other bookmarks.
+func trashedSuffix(bookmark *models.Bookmark) string {
+	if bookmark.DeletedAt == nil {
+		return ""
+	}
+	return ", Deleted: " + bookmark.DeletedAt.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/fallrising/goku-cli/internal/bookmarks"
+	"github.com/urfave/cli/v2"
+)
+
+func VisitCommand() *cli.Command {
+	return &cli.Command{
+		Name: "visit",
+		Usage: "Record a visit to a bookmark\n\n" +
+			"Example:\n" +
+			"  goku visit --id 123",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{Name: "id", Required: true},
+		},
+		Action: func(c *cli.Context) error {
+			bookmarkService := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			if err := bookmarkService.RecordVisit(c.Context, c.Int64("id")); err != nil {
+				return fmt.Errorf("failed to record visit: %w", err)
+			}
+			fmt.Println("Visit recorded")
+			return nil
+		},
+	}
+}
@@ -49,9 +49,18 @@ func createApp() *cli.App {
 		Commands: getCommands(),
 		Before: func(c *cli.Context) error {
 			bookmarkService := setupDatabases(c)
+			bookmarkService.SetStripWWW(c.Bool("strip-www"))
+			bookmarkService.AddTrackingParams(c.StringSlice("tracking-param")...)
 			c.App.Metadata["bookmarkService"] = bookmarkService
 			return nil
 		},
+		After: func(c *cli.Context) error {
+			bookmarkService, ok := c.App.Metadata["bookmarkService"].(*bookmarks.BookmarkService)
+			if !ok {
+				return nil
+			}
+			return bookmarkService.Close()
+		},
 	}
 
 	sort.Sort(cli.CommandsByName(app.Commands))
@@ -66,7 +75,12 @@ func setupDatabases(c *cli.Context) *bookmarks.BookmarkService {
 	cacheDBPath := getEnvOrDefault(fmt.Sprintf("GOKU_CACHE_DB_PATH_%s", strings.ToUpper(user)), fmt.Sprintf("%s_cache.db", user))
 	duckDBPath := getEnvOrDefault(fmt.Sprintf("GOKU_DUCKDB_PATH_%s", strings.ToUpper(user)), fmt.Sprintf("%s_stats.duckdb", user))
 
-	db, err := database.NewDatabase(dbPath, cacheDBPath)
+	options := database.ConnectionOptions{
+		JournalMode: c.String("journal-mode"),
+		BusyTimeout: c.Duration("busy-timeout"),
+		Synchronous: c.String("synchronous"),
+	}
+	db, err := database.NewDatabase(dbPath, cacheDBPath, user, options)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -74,6 +88,7 @@ func setupDatabases(c *cli.Context) *bookmarks.BookmarkService {
 	if err := db.Init(); err != nil {
 		log.Fatalf("Failed to initialize database schema: %v", err)
 	}
+	db.SetRelationalTags(c.Bool("relational-tags"))
 
 	duckDBStats, err := database.NewDuckDBStats(duckDBPath)
 	if err != nil {
@@ -120,24 +135,72 @@ func getGlobalFlags() []cli.Flag {
 			Value:   "goku",
 			Usage:   "User profile to use (determines which database to connect to)",
 		},
+		&cli.BoolFlag{
+			Name:    "strip-www",
+			EnvVars: []string{"GOKU_STRIP_WWW"},
+			Usage:   "Treat www.example.com and example.com as the same host in duplicate checks and host filters",
+		},
+		&cli.BoolFlag{
+			Name:    "relational-tags",
+			EnvVars: []string{"GOKU_RELATIONAL_TAGS"},
+			Value:   true,
+			Usage:   "Read, filter, list and count tags using the tags/bookmark_tags tables; =false falls back to the comma-separated tags column",
+		},
+		&cli.StringSliceFlag{
+			Name:    "tracking-param",
+			EnvVars: []string{"GOKU_TRACKING_PARAMS"},
+			Usage:   "Extra query parameter to strip from added URLs, on top of utm_*, fbclid, gclid and the like (repeatable; a trailing * matches a prefix)",
+		},
+		&cli.StringFlag{
+			Name:    "journal-mode",
+			EnvVars: []string{"GOKU_JOURNAL_MODE"},
+			Value:   database.DefaultConnectionOptions.JournalMode,
+			Usage:   "SQLite journal mode of the databases, e.g. WAL or DELETE",
+		},
+		&cli.DurationFlag{
+			Name:    "busy-timeout",
+			EnvVars: []string{"GOKU_BUSY_TIMEOUT"},
+			Value:   database.DefaultConnectionOptions.BusyTimeout,
+			Usage:   "How long to wait for a database locked by another goku process",
+		},
+		&cli.StringFlag{
+			Name:    "synchronous",
+			EnvVars: []string{"GOKU_SYNCHRONOUS"},
+			Value:   database.DefaultConnectionOptions.Synchronous,
+			Usage:   "SQLite synchronous setting of the databases: OFF, NORMAL, FULL or EXTRA",
+		},
 	}
 }
 
 func getCommands() []*cli.Command {
 	return []*cli.Command{
 		commands.AddCommand(),
+		commands.AnalyticsCommand(),
 		commands.DeleteCommand(),
+		commands.RestoreCommand(),
+		commands.TrashCommand(),
+		commands.FavoriteCommand(),
+		commands.UnfavoriteCommand(),
+		commands.VisitCommand(),
+		commands.OpenCommand(),
 		commands.GetCommand(),
 		commands.ListCommand(),
 		commands.SearchCommand(),
 		commands.UpdateCommand(),
+		commands.EditCommand(),
 		commands.ImportCommand(),
 		commands.ExportCommand(),
 		commands.TagsCommand(),
 		commands.StatsCommand(),
 		commands.PurgeCommand(),
 		commands.SyncCommand(),
+		commands.BackupCommand(),
 		commands.FetchCommand(),
+		commands.AuditCommand(),
+		commands.CheckCommand(),
+		commands.CountCommand(),
+		commands.DedupCommand(),
+		commands.DiffCommand(),
 	}
 }
 
@@ -0,0 +1,152 @@
+package bookmarks
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// ContentTypeResult pairs a bookmark with the content type its URL is served with.
+// Err is set when the content type could not be determined.
+type ContentTypeResult struct {
+	Bookmark    *models.Bookmark
+	ContentType string
+	Err         error
+}
+
+// AuditNonHTML checks every bookmark's content type and returns those not served as
+// HTML, along with bookmarks whose content type could not be checked.
+func (s *BookmarkService) AuditNonHTML(ctx context.Context, numWorkers int) ([]ContentTypeResult, error) {
+	probed, err := s.probeAllBookmarks(ctx, numWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ContentTypeResult
+	for _, result := range probed {
+		if result.Err == nil && isHTMLContentType(result.ContentType) {
+			continue
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// HostLinkReport counts reachable and unreachable links on one host.
+type HostLinkReport struct {
+	Hostname    string
+	Reachable   int
+	Unreachable int
+	Dead        []ContentTypeResult // the unreachable links, with the reason in Err
+}
+
+// AllDead reports whether every link on the host is unreachable, which usually means
+// the whole site is gone rather than individual pages.
+func (r *HostLinkReport) AllDead() bool {
+	return r.Reachable == 0 && r.Unreachable > 0
+}
+
+// CheckLinks requests every bookmark's URL and groups the outcome by hostname. Hosts
+// with the most unreachable links come first.
+func (s *BookmarkService) CheckLinks(ctx context.Context, numWorkers int) ([]*HostLinkReport, error) {
+	probed, err := s.probeAllBookmarks(ctx, numWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	byHost := make(map[string]*HostLinkReport)
+	for _, result := range probed {
+		hostname := extractHostname(result.Bookmark.URL)
+		report, ok := byHost[hostname]
+		if !ok {
+			report = &HostLinkReport{Hostname: hostname}
+			byHost[hostname] = report
+		}
+		if result.Err != nil {
+			report.Unreachable++
+			report.Dead = append(report.Dead, result)
+		} else {
+			report.Reachable++
+		}
+	}
+
+	reports := make([]*HostLinkReport, 0, len(byHost))
+	for _, report := range byHost {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Unreachable != reports[j].Unreachable {
+			return reports[i].Unreachable > reports[j].Unreachable
+		}
+		return reports[i].Hostname < reports[j].Hostname
+	})
+	return reports, nil
+}
+
+// probeAllBookmarks requests every bookmark's URL concurrently and returns the
+// results ordered by bookmark ID.
+func (s *BookmarkService) probeAllBookmarks(ctx context.Context, numWorkers int) ([]ContentTypeResult, error) {
+	if numWorkers <= 0 {
+		numWorkers = 5
+	}
+
+	allBookmarks, err := s.listAllBookmarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Probing %d bookmarks", len(allBookmarks))
+
+	bookmarkChan := make(chan *models.Bookmark, 100)
+	resultChan := make(chan ContentTypeResult, 100)
+	var wg sync.WaitGroup
+
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for bookmark := range bookmarkChan {
+				contentType, err := s.fetcher.ContentType(bookmark.URL)
+				resultChan <- ContentTypeResult{Bookmark: bookmark, ContentType: contentType, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, bookmark := range allBookmarks {
+			bookmarkChan <- bookmark
+		}
+		close(bookmarkChan)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	var results []ContentTypeResult
+	for result := range resultChan {
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Bookmark.ID < results[j].Bookmark.ID })
+	return results, nil
+}
+
+// extractHostname returns the lowercased host of rawURL without a leading "www.",
+// or rawURL itself when it has no host.
+func extractHostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return rawURL
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+func isHTMLContentType(contentType string) bool {
+	return contentType == "text/html" || contentType == "application/xhtml+xml"
+}
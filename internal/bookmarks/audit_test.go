@@ -0,0 +1,20 @@
+package bookmarks
+
+import "testing"
+
+func TestExtractHostname(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://www.Example.com/path", "example.com"},
+		{"http://blog.example.com:8080/", "blog.example.com"},
+		{"not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := extractHostname(tt.url); got != tt.want {
+			t.Errorf("extractHostname(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
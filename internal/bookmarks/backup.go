@@ -0,0 +1,17 @@
+package bookmarks
+
+import (
+	"context"
+)
+
+// Backup writes a consistent snapshot of the bookmark database to path, which must
+// not exist yet. Other goku processes can keep using the database meanwhile.
+func (s *BookmarkService) Backup(ctx context.Context, path string) error {
+	return s.repo.BackupTo(ctx, path)
+}
+
+// RestoreBackup replaces every bookmark, including the trash, with those in the
+// backup at path, made by Backup.
+func (s *BookmarkService) RestoreBackup(ctx context.Context, path string) error {
+	return s.repo.RestoreFrom(ctx, path)
+}
@@ -0,0 +1,180 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// DuplicateGroup is a set of bookmarks for the same page: Keep survives with Tags,
+// the union of every bookmark's tags, and Remove are deleted.
+type DuplicateGroup struct {
+	Keep   *models.Bookmark
+	Remove []*models.Bookmark
+	Tags   []string
+}
+
+// FindSchemeDuplicates groups bookmarks whose URLs differ only in http:// versus
+// https://. The https bookmark is kept; if there are several, the oldest one.
+func (s *BookmarkService) FindSchemeDuplicates(ctx context.Context) ([]DuplicateGroup, error) {
+	all, err := s.listAllBookmarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bookmarks are listed by ID, so each group is in insertion order
+	byAddress := make(map[string][]*models.Bookmark)
+	var addresses []string
+	for _, bookmark := range all {
+		address, ok := withoutScheme(bookmark.URL)
+		if !ok {
+			continue
+		}
+		if _, seen := byAddress[address]; !seen {
+			addresses = append(addresses, address)
+		}
+		byAddress[address] = append(byAddress[address], bookmark)
+	}
+
+	var groups []DuplicateGroup
+	for _, address := range addresses {
+		group, ok := schemeDuplicateGroup(byAddress[address])
+		if ok {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// FindURLDuplicates groups bookmarks whose URLs are the same once normalized as for
+// FindSimilarBookmarks: without scheme, "www.", tracking parameters, fragment and
+// trailing slashes, and with the query sorted. The oldest bookmark of each group is
+// kept.
+func (s *BookmarkService) FindURLDuplicates(ctx context.Context) ([]DuplicateGroup, error) {
+	all, err := s.listAllBookmarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bookmarks are listed by ID, so each group is in insertion order
+	byKey := make(map[string][]*models.Bookmark)
+	var keys []string
+	for _, bookmark := range all {
+		if _, ok := withoutScheme(bookmark.URL); !ok {
+			continue
+		}
+		host, key := s.similarityURL(bookmark.URL)
+		if host == "" {
+			continue
+		}
+		if _, seen := byKey[key]; !seen {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], bookmark)
+	}
+
+	var groups []DuplicateGroup
+	for _, key := range keys {
+		bookmarks := byKey[key]
+		if len(bookmarks) < 2 {
+			continue
+		}
+		oldest := 0
+		for i, bookmark := range bookmarks {
+			if bookmark.CreatedAt.Before(bookmarks[oldest].CreatedAt) {
+				oldest = i
+			}
+		}
+		group := DuplicateGroup{Keep: bookmarks[oldest], Tags: mergedTags(bookmarks)}
+		for i, bookmark := range bookmarks {
+			if i != oldest {
+				group.Remove = append(group.Remove, bookmark)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// withoutScheme returns url without its http:// or https:// prefix.
+func withoutScheme(url string) (string, bool) {
+	lower := strings.ToLower(url)
+	for _, scheme := range []string{"https://", "http://"} {
+		if strings.HasPrefix(lower, scheme) {
+			return url[len(scheme):], true
+		}
+	}
+	return "", false
+}
+
+// schemeDuplicateGroup picks the https bookmark to keep from bookmarks sharing an
+// address and marks the http ones for removal. It reports false unless both schemes
+// are present.
+func schemeDuplicateGroup(bookmarks []*models.Bookmark) (DuplicateGroup, bool) {
+	var group DuplicateGroup
+	var insecure []*models.Bookmark
+	for _, bookmark := range bookmarks {
+		if strings.HasPrefix(strings.ToLower(bookmark.URL), "https://") {
+			if group.Keep == nil {
+				group.Keep = bookmark
+			}
+		} else {
+			insecure = append(insecure, bookmark)
+		}
+	}
+	if group.Keep == nil || len(insecure) == 0 {
+		return DuplicateGroup{}, false
+	}
+
+	group.Remove = insecure
+	group.Tags = mergedTags(append([]*models.Bookmark{group.Keep}, insecure...))
+	return group, true
+}
+
+// mergedTags returns the union of the bookmarks' tags, sorted, without empty tags.
+func mergedTags(bookmarks []*models.Bookmark) []string {
+	seen := make(map[string]struct{})
+	var tags []string
+	for _, bookmark := range bookmarks {
+		for _, tag := range bookmark.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if _, ok := seen[tag]; !ok {
+				seen[tag] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// MergeDuplicates gives group.Keep the merged tags, and makes it a favorite if any
+// bookmark in the group was one, then deletes the other bookmarks in the group.
+func (s *BookmarkService) MergeDuplicates(ctx context.Context, group DuplicateGroup) error {
+	favorite := group.Keep.Favorite
+	for _, bookmark := range group.Remove {
+		favorite = favorite || bookmark.Favorite
+	}
+	if !equalTags(group.Keep.Tags, group.Tags) || favorite != group.Keep.Favorite {
+		group.Keep.Tags = group.Tags
+		group.Keep.Favorite = favorite
+		if err := s.repo.Update(ctx, group.Keep); err != nil {
+			return fmt.Errorf("failed to update bookmark %d: %w", group.Keep.ID, err)
+		}
+	}
+
+	for _, bookmark := range group.Remove {
+		if err := s.repo.Delete(ctx, bookmark.ID); err != nil {
+			return fmt.Errorf("failed to delete bookmark %d: %w", bookmark.ID, err)
+		}
+		log.Printf("Merged bookmark %d (%s) into %d (%s)", bookmark.ID, bookmark.URL, group.Keep.ID, group.Keep.URL)
+	}
+	return nil
+}
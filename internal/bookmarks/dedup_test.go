@@ -0,0 +1,158 @@
+package bookmarks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestSchemeDuplicates(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "http://example.com/a", Title: "a", Tags: []string{"old", "web"}, Favorite: true},
+		{URL: "https://example.com/a", Title: "a", Tags: []string{"web"}},
+		{URL: "http://insecure-only.example", Title: "b", Tags: []string{"x"}},
+		{URL: "https://example.com/b", Title: "c", Tags: []string{"x"}},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	groups, err := s.FindSchemeDuplicates(ctx)
+	if err != nil {
+		t.Fatalf("FindSchemeDuplicates: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(groups))
+	}
+	group := groups[0]
+	if group.Keep.URL != "https://example.com/a" || len(group.Remove) != 1 || group.Remove[0].URL != "http://example.com/a" {
+		t.Fatalf("group keeps %s and removes %v", group.Keep.URL, group.Remove)
+	}
+
+	if err := s.MergeDuplicates(ctx, group); err != nil {
+		t.Fatalf("MergeDuplicates: %v", err)
+	}
+
+	kept, err := s.GetBookmark(ctx, group.Keep.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark: %v", err)
+	}
+	if want := []string{"old", "web"}; !reflect.DeepEqual(kept.Tags, want) {
+		t.Errorf("kept tags = %v, want %v", kept.Tags, want)
+	}
+	if !kept.Favorite {
+		t.Errorf("kept bookmark is not a favorite, though the http one was")
+	}
+	if removed, _ := s.repo.GetByID(ctx, group.Remove[0].ID); removed != nil {
+		t.Errorf("http bookmark %d was not deleted", group.Remove[0].ID)
+	}
+	if count, _ := s.CountBookmarks(ctx); count != 3 {
+		t.Errorf("%d bookmarks left, want 3", count)
+	}
+}
+
+func TestFindSimilarBookmarks(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://example.com/post?id=7&lang=en", Title: "Post seven"},
+		{URL: "http://www.example.com/post/?lang=en&id=7#comments", Title: "Something else"},
+		{URL: "https://other.example/guide", Title: "The Complete Guide to SQLite Indexes"},
+		{URL: "https://mirror.example/sqlite", Title: "The Complete Guide to SQLite Indexes!"},
+		{URL: "https://example.com/post?id=8&lang=en", Title: "Post eight"},
+		{URL: "https://unrelated.example", Title: "Unrelated"},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	groups, err := s.FindSimilarBookmarks(ctx, 0.9)
+	if err != nil {
+		t.Fatalf("FindSimilarBookmarks: %v", err)
+	}
+	var got [][]string
+	for _, group := range groups {
+		var urls []string
+		for _, bookmark := range group.Bookmarks {
+			urls = append(urls, bookmark.URL)
+		}
+		got = append(got, urls)
+	}
+	want := [][]string{
+		{"https://example.com/post?id=7&lang=en", "http://www.example.com/post/?lang=en&id=7#comments"},
+		{"https://other.example/guide", "https://mirror.example/sqlite"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+
+	if count, _ := s.CountBookmarks(ctx); count != 6 {
+		t.Errorf("%d bookmarks left, want 6", count)
+	}
+	if _, err := s.FindSimilarBookmarks(ctx, 0); err == nil {
+		t.Error("FindSimilarBookmarks(0) succeeded, want an error")
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	if got := trigramSimilarity(trigrams("golang"), trigrams("golang")); got != 1 {
+		t.Errorf("identical = %v, want 1", got)
+	}
+	if got := trigramSimilarity(trigrams("golang"), trigrams("")); got != 0 {
+		t.Errorf("empty = %v, want 0", got)
+	}
+	if got := trigramSimilarity(trigrams("abc"), trigrams("xyz")); got != 0 {
+		t.Errorf("disjoint = %v, want 0", got)
+	}
+}
+
+func TestURLDuplicates(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "http://www.example.com/post/", Title: "oldest", Tags: []string{"a"}},
+		{URL: "https://example.com/post?utm_source=feed", Title: "tracked", Tags: []string{"b"}, Favorite: true},
+		{URL: "https://example.com/post#comments", Title: "fragment", Tags: []string{"a", "c"}},
+		{URL: "https://example.com/other", Title: "other"},
+		// A different port is a different site
+		{URL: "https://example.com:8443/post", Title: "other port"},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	groups, err := s.FindURLDuplicates(ctx)
+	if err != nil {
+		t.Fatalf("FindURLDuplicates: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Remove) != 2 {
+		t.Fatalf("groups = %+v, want one group removing 2 bookmarks", groups)
+	}
+	group := groups[0]
+	if group.Keep.Title != "oldest" {
+		t.Errorf("keeps %q, want the oldest bookmark", group.Keep.Title)
+	}
+
+	if err := s.MergeDuplicates(ctx, group); err != nil {
+		t.Fatalf("MergeDuplicates: %v", err)
+	}
+	kept, err := s.GetBookmark(ctx, group.Keep.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark: %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(kept.Tags, want) || !kept.Favorite {
+		t.Errorf("kept tags = %v, favorite %v; want %v and a favorite", kept.Tags, kept.Favorite, want)
+	}
+	if count, _ := s.CountBookmarks(ctx); count != 3 {
+		t.Errorf("%d bookmarks left, want 3", count)
+	}
+}
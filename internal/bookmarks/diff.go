@@ -0,0 +1,132 @@
+package bookmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// ExportDiff lists how the bookmarks of one export differ from another's, matching
+// bookmarks by URL.
+type ExportDiff struct {
+	Added    []*models.Bookmark // only in the new export
+	Removed  []*models.Bookmark // only in the old export
+	Modified []BookmarkChange   // in both, with a different title, description or tags
+}
+
+// BookmarkChange is a bookmark present in both exports, with the fields that differ.
+type BookmarkChange struct {
+	Old, New *models.Bookmark
+	Fields   []string // "title", "description" and/or "tags"
+}
+
+// Empty reports whether the two exports hold the same bookmarks.
+func (d *ExportDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// ReadExport reads the bookmarks of a JSON export: either a JSON array, as written by
+// export --format json, or JSON Lines as written by export --format jsonl. Tags may be
+// an array or a comma-separated string. Repeated URLs keep their first entry.
+func ReadExport(r io.Reader) ([]*models.Bookmark, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("[")) {
+		bookmarks, err := parseLegacyJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON export: %w", err)
+		}
+		return bookmarks, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	seen := make(map[string]struct{})
+	var bookmarks []*models.Bookmark
+	for line := 1; ; line++ {
+		var item legacyBookmark
+		err := decoder.Decode(&item)
+		if errors.Is(err, io.EOF) {
+			return bookmarks, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON Lines export at entry %d: %w", line, err)
+		}
+		if _, exists := seen[item.URL]; exists {
+			continue
+		}
+		seen[item.URL] = struct{}{}
+		bookmarks = append(bookmarks, &models.Bookmark{
+			URL:         item.URL,
+			Title:       item.Title,
+			Description: item.Description,
+			Tags:        item.Tags,
+			CreatedAt:   item.CreatedAt,
+		})
+	}
+}
+
+// DiffExports compares the bookmarks of two exports by URL. Each list in the result
+// is sorted by URL; tag order does not count as a change.
+func DiffExports(oldBookmarks, newBookmarks []*models.Bookmark) *ExportDiff {
+	oldByURL := make(map[string]*models.Bookmark, len(oldBookmarks))
+	for _, bookmark := range oldBookmarks {
+		oldByURL[bookmark.URL] = bookmark
+	}
+	newByURL := make(map[string]*models.Bookmark, len(newBookmarks))
+	for _, bookmark := range newBookmarks {
+		newByURL[bookmark.URL] = bookmark
+	}
+
+	diff := &ExportDiff{}
+	for url, newBookmark := range newByURL {
+		oldBookmark, ok := oldByURL[url]
+		if !ok {
+			diff.Added = append(diff.Added, newBookmark)
+			continue
+		}
+		if fields := changedFields(oldBookmark, newBookmark); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, BookmarkChange{Old: oldBookmark, New: newBookmark, Fields: fields})
+		}
+	}
+	for url, oldBookmark := range oldByURL {
+		if _, ok := newByURL[url]; !ok {
+			diff.Removed = append(diff.Removed, oldBookmark)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].URL < diff.Added[j].URL })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].URL < diff.Removed[j].URL })
+	sort.Slice(diff.Modified, func(i, j int) bool { return diff.Modified[i].New.URL < diff.Modified[j].New.URL })
+	return diff
+}
+
+// changedFields returns the names of the fields that differ between two versions of
+// a bookmark.
+func changedFields(oldBookmark, newBookmark *models.Bookmark) []string {
+	var fields []string
+	if oldBookmark.Title != newBookmark.Title {
+		fields = append(fields, "title")
+	}
+	if oldBookmark.Description != newBookmark.Description {
+		fields = append(fields, "description")
+	}
+	if strings.Join(sortedTags(oldBookmark.Tags), ",") != strings.Join(sortedTags(newBookmark.Tags), ",") {
+		fields = append(fields, "tags")
+	}
+	return fields
+}
+
+// sortedTags returns a sorted copy of tags.
+func sortedTags(tags []string) []string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,67 @@
+package bookmarks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadExport(t *testing.T) {
+	array := `[{"url": "https://a.example", "title": "A", "tags": "go, web"},
+		{"url": "https://a.example", "title": "A again"}]`
+	lines := `{"id": 1, "url": "https://a.example", "title": "A", "tags": ["go", "web"], "kind": "page"}
+{"id": 2, "url": "https://b.example", "title": "B", "tags": null}
+`
+	for name, input := range map[string]string{"array": array, "json lines": lines} {
+		exported, err := ReadExport(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: ReadExport: %v", name, err)
+		}
+		if exported[0].URL != "https://a.example" || exported[0].Title != "A" || !reflect.DeepEqual(exported[0].Tags, []string{"go", "web"}) {
+			t.Errorf("%s: first bookmark = %+v", name, exported[0])
+		}
+	}
+
+	if _, err := ReadExport(strings.NewReader("{\"url\": \"https://a.example\"}\nnot json\n")); err == nil {
+		t.Error("malformed JSON Lines: want an error")
+	}
+}
+
+func TestDiffExports(t *testing.T) {
+	oldExport, err := ReadExport(strings.NewReader(`[
+		{"url": "https://kept.example", "title": "Kept", "tags": "a,b"},
+		{"url": "https://retitled.example", "title": "Before", "tags": "a"},
+		{"url": "https://retagged.example", "title": "T", "tags": "a"},
+		{"url": "https://gone.example", "title": "Gone"}]`))
+	if err != nil {
+		t.Fatalf("ReadExport(old): %v", err)
+	}
+	newExport, err := ReadExport(strings.NewReader(`[
+		{"url": "https://kept.example", "title": "Kept", "tags": "b,a"},
+		{"url": "https://retitled.example", "title": "After", "tags": "a"},
+		{"url": "https://retagged.example", "title": "T", "tags": "a,c"},
+		{"url": "https://new.example", "title": "New"}]`))
+	if err != nil {
+		t.Fatalf("ReadExport(new): %v", err)
+	}
+
+	diff := DiffExports(oldExport, newExport)
+	if len(diff.Added) != 1 || diff.Added[0].URL != "https://new.example" {
+		t.Errorf("Added = %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].URL != "https://gone.example" {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+	var modified []string
+	for _, change := range diff.Modified {
+		modified = append(modified, change.New.URL+" "+strings.Join(change.Fields, ","))
+	}
+	want := []string{"https://retagged.example tags", "https://retitled.example title"}
+	if !reflect.DeepEqual(modified, want) {
+		t.Errorf("Modified = %v, want %v", modified, want)
+	}
+
+	if !DiffExports(newExport, newExport).Empty() {
+		t.Error("an export compared with itself is not Empty")
+	}
+}
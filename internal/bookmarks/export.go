@@ -2,13 +2,21 @@ package bookmarks
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"github.com/fallrising/goku-cli/pkg/models"
 	"github.com/schollz/progressbar/v3"
 	"golang.org/x/net/html"
+	"io"
+	"sort"
 	"strings"
+	"time"
 )
 
-func (s *BookmarkService) ExportToHTML(ctx context.Context) (string, error) {
+// ExportToHTML renders every bookmark as a Netscape bookmark file, in the given order.
+// Exporting an unchanged library twice gives identical files.
+func (s *BookmarkService) ExportToHTML(ctx context.Context, sort models.SortField) (string, error) {
 	const pageSize = 100 // Number of bookmarks to fetch per page
 
 	// Get total count of bookmarks
@@ -20,36 +28,235 @@ func (s *BookmarkService) ExportToHTML(ctx context.Context) (string, error) {
 	bar := progressbar.Default(int64(totalCount))
 
 	var sb strings.Builder
-
-	// Write HTML header
-	sb.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
-	sb.WriteString("<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
-	sb.WriteString("<TITLE>Bookmarks</TITLE>\n")
-	sb.WriteString("<H1>Bookmarks</H1>\n")
-	sb.WriteString("<DL><p>\n")
+	writeHTMLHeader(&sb)
 
 	// Fetch and write bookmarks in batches
 	for offset := 0; offset < totalCount; offset += pageSize {
-		bookmarks, err := s.ListBookmarks(ctx, pageSize, offset)
+		bookmarks, err := s.ListBookmarksFiltered(ctx, models.BookmarkFilter{Sort: sort}, pageSize, offset)
 		if err != nil {
 			return "", fmt.Errorf("failed to fetch bookmarks at offset %d: %w", offset, err)
 		}
 
 		for _, bookmark := range bookmarks {
-			sb.WriteString(fmt.Sprintf("    <DT><A HREF=\"%s\" ADD_DATE=\"%d\">%s</A>\n",
-				html.EscapeString(bookmark.URL),
-				bookmark.CreatedAt.Unix(),
-				html.EscapeString(bookmark.Title)))
+			writeHTMLBookmark(&sb, bookmark)
+			bar.Add(1)
+		}
+	}
+
+	writeHTMLFooter(&sb)
+	return sb.String(), nil
+}
+
+// ExportSampleToHTML exports n randomly chosen bookmarks, for spot-checking the
+// quality of a large library without exporting all of it.
+func (s *BookmarkService) ExportSampleToHTML(ctx context.Context, n int) (string, error) {
+	if n <= 0 {
+		return "", fmt.Errorf("sample size must be greater than zero")
+	}
+
+	bookmarks, err := s.repo.ListRandom(ctx, n)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch random bookmarks: %w", err)
+	}
+
+	var sb strings.Builder
+	writeHTMLHeader(&sb)
+	for _, bookmark := range bookmarks {
+		writeHTMLBookmark(&sb, bookmark)
+	}
+	writeHTMLFooter(&sb)
+
+	return sb.String(), nil
+}
+
+// ExportToJSONL writes every bookmark to w as one JSON object per line, in the given
+// order, a page at a time, so large libraries can be exported without holding them in
+// memory.
+func (s *BookmarkService) ExportToJSONL(ctx context.Context, w io.Writer, sort models.SortField) error {
+	const pageSize = 100
 
-			if bookmark.Description != "" {
-				sb.WriteString(fmt.Sprintf("    <DD>%s\n", html.EscapeString(bookmark.Description)))
+	encoder := json.NewEncoder(w)
+	for offset := 0; ; offset += pageSize {
+		bookmarks, err := s.ListBookmarksFiltered(ctx, models.BookmarkFilter{Sort: sort}, pageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to fetch bookmarks at offset %d: %w", offset, err)
+		}
+		if len(bookmarks) == 0 {
+			return nil
+		}
+
+		for _, bookmark := range bookmarks {
+			if err := encoder.Encode(bookmark); err != nil {
+				return fmt.Errorf("failed to write bookmark %d: %w", bookmark.ID, err)
 			}
-			bar.Add(1)
 		}
 	}
+}
 
-	// Close HTML
-	sb.WriteString("</DL><p>")
+// ExportToJSON renders every bookmark as an indented JSON array, in the given order.
+// The result can be imported again with ImportFromJSON.
+func (s *BookmarkService) ExportToJSON(ctx context.Context, sort models.SortField) (string, error) {
+	const pageSize = 100
+
+	all := []*models.Bookmark{}
+	for offset := 0; ; offset += pageSize {
+		bookmarks, err := s.ListBookmarksFiltered(ctx, models.BookmarkFilter{Sort: sort}, pageSize, offset)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch bookmarks at offset %d: %w", offset, err)
+		}
+		if len(bookmarks) == 0 {
+			break
+		}
+		all = append(all, bookmarks...)
+	}
+
+	content, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode bookmarks: %w", err)
+	}
+	return string(content), nil
+}
+
+// ExportToMarkdown renders every bookmark as a Markdown document with a "## tag"
+// section per tag, in alphabetical order, listing "- [Title](URL) — description"
+// bullets in the given order. A bookmark with several tags is listed under each, and
+// bookmarks without tags are listed under "Untagged" at the end.
+func (s *BookmarkService) ExportToMarkdown(ctx context.Context, order models.SortField) (string, error) {
+	const pageSize = 100
+
+	byTag := make(map[string][]*models.Bookmark)
+	var untagged []*models.Bookmark
+	for offset := 0; ; offset += pageSize {
+		bookmarks, err := s.ListBookmarksFiltered(ctx, models.BookmarkFilter{Sort: order}, pageSize, offset)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch bookmarks at offset %d: %w", offset, err)
+		}
+		if len(bookmarks) == 0 {
+			break
+		}
 
+		for _, bookmark := range bookmarks {
+			tagged := false
+			seen := make(map[string]bool)
+			for _, tag := range bookmark.Tags {
+				tag = strings.TrimSpace(tag)
+				if tag == "" || seen[tag] {
+					continue
+				}
+				seen[tag] = true
+				byTag[tag] = append(byTag[tag], bookmark)
+				tagged = true
+			}
+			if !tagged {
+				untagged = append(untagged, bookmark)
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var sb strings.Builder
+	sb.WriteString("# Bookmarks\n")
+	for _, tag := range tags {
+		writeMarkdownSection(&sb, tag, byTag[tag])
+	}
+	if len(untagged) > 0 {
+		writeMarkdownSection(&sb, "Untagged", untagged)
+	}
 	return sb.String(), nil
 }
+
+// markdownEscaper escapes the characters that would end a link text early or start
+// other markup inside it.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`")
+
+// markdownURLEscaper keeps a URL from ending a link target early.
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
+
+func writeMarkdownSection(sb *strings.Builder, heading string, bookmarks []*models.Bookmark) {
+	sb.WriteString(fmt.Sprintf("\n## %s\n\n", heading))
+	for _, bookmark := range bookmarks {
+		title := bookmark.Title
+		if strings.TrimSpace(title) == "" {
+			title = bookmark.URL
+		}
+		sb.WriteString(fmt.Sprintf("- [%s](%s)", markdownEscaper.Replace(strings.Join(strings.Fields(title), " ")), markdownURLEscaper.Replace(bookmark.URL)))
+		if description := strings.Join(strings.Fields(bookmark.Description), " "); description != "" {
+			sb.WriteString(" — " + description)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// csvHeader names the columns written by ExportToCSV.
+var csvHeader = []string{"id", "url", "title", "description", "tags", "created_at", "updated_at"}
+
+// ExportToCSV writes every bookmark to w as CSV with a header row, in the given order,
+// a page at a time. Tags are joined with semicolons and timestamps are RFC 3339.
+func (s *BookmarkService) ExportToCSV(ctx context.Context, w io.Writer, sort models.SortField) error {
+	const pageSize = 100
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for offset := 0; ; offset += pageSize {
+		bookmarks, err := s.ListBookmarksFiltered(ctx, models.BookmarkFilter{Sort: sort}, pageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to fetch bookmarks at offset %d: %w", offset, err)
+		}
+		if len(bookmarks) == 0 {
+			break
+		}
+
+		for _, bookmark := range bookmarks {
+			record := []string{
+				fmt.Sprint(bookmark.ID),
+				bookmark.URL,
+				bookmark.Title,
+				bookmark.Description,
+				strings.Join(bookmark.Tags, ";"),
+				bookmark.CreatedAt.Format(time.RFC3339),
+				bookmark.UpdatedAt.Format(time.RFC3339),
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write bookmark %d: %w", bookmark.ID, err)
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeHTMLHeader(sb *strings.Builder) {
+	sb.WriteString("<!DOCTYPE NETSCAPE-Bookmark-file-1>\n")
+	sb.WriteString("<META HTTP-EQUIV=\"Content-Type\" CONTENT=\"text/html; charset=UTF-8\">\n")
+	sb.WriteString("<TITLE>Bookmarks</TITLE>\n")
+	sb.WriteString("<H1>Bookmarks</H1>\n")
+	sb.WriteString("<DL><p>\n")
+}
+
+func writeHTMLBookmark(sb *strings.Builder, bookmark *models.Bookmark) {
+	// LAST_MODIFIED is optional in the Netscape format, so only emit it when we know it
+	lastModified := ""
+	if !bookmark.UpdatedAt.IsZero() {
+		lastModified = fmt.Sprintf(" LAST_MODIFIED=\"%d\"", bookmark.UpdatedAt.Unix())
+	}
+	sb.WriteString(fmt.Sprintf("    <DT><A HREF=\"%s\" ADD_DATE=\"%d\"%s>%s</A>\n",
+		html.EscapeString(bookmark.URL),
+		bookmark.CreatedAt.Unix(),
+		lastModified,
+		html.EscapeString(bookmark.Title)))
+
+	if bookmark.Description != "" {
+		sb.WriteString(fmt.Sprintf("    <DD>%s\n", html.EscapeString(bookmark.Description)))
+	}
+}
+
+func writeHTMLFooter(sb *strings.Builder) {
+	sb.WriteString("</DL><p>")
+}
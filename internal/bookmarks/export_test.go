@@ -0,0 +1,204 @@
+package bookmarks
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestExportToJSONL(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, u := range []string{"https://a.example", "https://b.example"} {
+		if err := s.CreateBookmark(ctx, &models.Bookmark{URL: u, Title: u, Tags: []string{"t"}}); err != nil {
+			t.Fatalf("CreateBookmark(%s): %v", u, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.ExportToJSONL(ctx, &buf, models.SortByID); err != nil {
+		t.Fatalf("ExportToJSONL: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{"https://a.example", "https://b.example"} {
+		var bookmark models.Bookmark
+		if err := json.Unmarshal([]byte(lines[i]), &bookmark); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v", i+1, err)
+		}
+		if bookmark.URL != want {
+			t.Errorf("line %d URL = %q, want %q", i+1, bookmark.URL, want)
+		}
+	}
+}
+
+func TestExportToHTMLIsStable(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, u := range []string{"https://b.example", "https://a.example", "https://c.example"} {
+		if err := s.repo.Create(ctx, &models.Bookmark{URL: u, Title: u}); err != nil {
+			t.Fatalf("Create(%s): %v", u, err)
+		}
+	}
+
+	first, err := s.ExportToHTML(ctx, models.SortByID)
+	if err != nil {
+		t.Fatalf("ExportToHTML: %v", err)
+	}
+	second, err := s.ExportToHTML(ctx, models.SortByID)
+	if err != nil {
+		t.Fatalf("ExportToHTML: %v", err)
+	}
+	if first != second {
+		t.Errorf("exports of an unchanged library differ:\n%s\n---\n%s", first, second)
+	}
+	if b, a := strings.Index(first, "https://b.example"), strings.Index(first, "https://a.example"); b < 0 || a < 0 || b > a {
+		t.Errorf("bookmarks are not in insertion order:\n%s", first)
+	}
+}
+
+func TestExportToJSONRoundTrips(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	created := time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC)
+	visited := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	original := []*models.Bookmark{
+		{URL: "https://a.example", Title: "A", Description: "first", Tags: []string{"go", "web"}, Kind: "repo", Favorite: true,
+			CreatedAt: created, VisitCount: 3, LastVisited: &visited},
+		{URL: "https://b.example", Title: "B", Description: "line one\nline \"two\"", Tags: []string{"x"}, Language: "en", CreatedAt: created},
+	}
+	for _, bookmark := range original {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	exported, err := s.ExportToJSON(ctx, models.SortByID)
+	if err != nil {
+		t.Fatalf("ExportToJSON: %v", err)
+	}
+
+	restored := newTestService(t)
+	result, err := restored.ImportFromJSON(ctx, strings.NewReader(exported))
+	if err != nil {
+		t.Fatalf("ImportFromJSON: %v", err)
+	}
+	if result.Created != len(original) {
+		t.Fatalf("imported %d bookmarks, want %d", result.Created, len(original))
+	}
+
+	for _, want := range original {
+		got, err := restored.repo.GetByURL(ctx, want.URL)
+		if err != nil || got == nil {
+			t.Fatalf("GetByURL(%s) = %v, %v", want.URL, got, err)
+		}
+		if got.Title != want.Title || got.Description != want.Description || strings.Join(got.Tags, ",") != strings.Join(want.Tags, ",") ||
+			got.Kind != want.Kind || got.Language != want.Language || got.Favorite != want.Favorite {
+			t.Errorf("%s came back as %+v, want %+v", want.URL, got, want)
+		}
+		if !got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("%s created at = %v, want %v", want.URL, got.CreatedAt, want.CreatedAt)
+		}
+		if got.VisitCount != want.VisitCount || (got.LastVisited == nil) != (want.LastVisited == nil) ||
+			(want.LastVisited != nil && !got.LastVisited.Equal(*want.LastVisited)) {
+			t.Errorf("%s visits = %d at %v, want %d at %v", want.URL, got.VisitCount, got.LastVisited, want.VisitCount, want.LastVisited)
+		}
+	}
+}
+
+func TestExportToJSONEmpty(t *testing.T) {
+	s := newTestService(t)
+	exported, err := s.ExportToJSON(testContext(), models.SortByID)
+	if err != nil {
+		t.Fatalf("ExportToJSON: %v", err)
+	}
+	if exported != "[]" {
+		t.Errorf("empty export = %q, want []", exported)
+	}
+}
+
+func TestExportToCSV(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://a.example", Title: `Say "hi", twice`, Description: "line one\nline two", Tags: []string{"go", "web"}},
+		{URL: "https://b.example", Title: "B"},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := s.ExportToCSV(ctx, &buf, models.SortByID); err != nil {
+		t.Fatalf("ExportToCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want a header and 2 bookmarks: %v", len(records), records)
+	}
+	if !reflect.DeepEqual(records[0], csvHeader) {
+		t.Errorf("header = %v, want %v", records[0], csvHeader)
+	}
+	if want := []string{"https://a.example", `Say "hi", twice`, "line one\nline two", "go;web"}; !reflect.DeepEqual(records[1][1:5], want) {
+		t.Errorf("first row = %q, want %q", records[1][1:5], want)
+	}
+	if records[2][1] != "https://b.example" || records[2][4] != "" {
+		t.Errorf("second row = %q", records[2])
+	}
+}
+
+func TestExportToMarkdown(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://go.dev", Title: "Go [home]", Description: "The Go\nwebsite", Tags: []string{"web", "go"}},
+		{URL: "https://example.com/a(b)", Title: "", Tags: []string{"web"}},
+		{URL: "https://plain.example", Title: "Plain"},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	got, err := s.ExportToMarkdown(ctx, models.SortByID)
+	if err != nil {
+		t.Fatalf("ExportToMarkdown: %v", err)
+	}
+	want := `# Bookmarks
+
+## go
+
+- [Go \[home\]](https://go.dev) — The Go website
+
+## web
+
+- [Go \[home\]](https://go.dev) — The Go website
+- [https://example.com/a(b)](https://example.com/a%28b%29)
+
+## Untagged
+
+- [Plain](https://plain.example)
+`
+	if got != want {
+		t.Errorf("ExportToMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
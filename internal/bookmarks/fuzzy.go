@@ -0,0 +1,100 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// fuzzyMinScore is the share of the query's trigrams a bookmark's title or URL must
+// contain for FuzzySearchBookmarks to return it.
+const fuzzyMinScore = 0.5
+
+// FuzzySearchBookmarks ranks the bookmarks matching filter by how closely their title
+// or URL resembles query, tolerating typos and missing words, and returns the page of
+// best matches given by limit and offset along with the number of matches in all.
+// "tag:name" terms in query filter by tag, as in SearchBookmarksWithCount.
+func (s *BookmarkService) FuzzySearchBookmarks(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error) {
+	query, filter.Tags = splitTagTerms(query, filter.Tags)
+	queryTrigrams := trigrams(query)
+	if len(queryTrigrams) == 0 {
+		return nil, 0, fmt.Errorf("search query cannot be empty")
+	}
+
+	type scored struct {
+		bookmark *models.Bookmark
+		score    float64
+	}
+	var matches []scored
+	const pageSize = 100
+	for pageOffset := 0; ; pageOffset += pageSize {
+		page, err := s.ListBookmarksFiltered(ctx, filter, pageSize, pageOffset)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to search bookmarks: %w", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, bookmark := range page {
+			score := trigramScore(queryTrigrams, bookmark.Title)
+			if urlScore := trigramScore(queryTrigrams, bookmark.URL); urlScore > score {
+				score = urlScore
+			}
+			if score >= fuzzyMinScore {
+				matches = append(matches, scored{bookmark, score})
+			}
+		}
+	}
+
+	// Listing is in ID order, so equal scores stay in ID order
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	// Page as SQLite's LIMIT and OFFSET do: a negative offset starts at the first match
+	// and a negative limit returns every match
+	if offset < 0 {
+		offset = 0
+	}
+	if limit < 0 {
+		limit = len(matches)
+	}
+	var bookmarks []*models.Bookmark
+	for i := offset; i < len(matches) && len(bookmarks) < limit; i++ {
+		bookmarks = append(bookmarks, matches[i].bookmark)
+	}
+	return bookmarks, len(matches), nil
+}
+
+// trigrams returns the set of three-character sequences in the words of s, lowercased
+// and padded as PostgreSQL's pg_trgm does, so word starts and ends count too.
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// trigramScore returns the share of queryTrigrams found in text, from 0 to 1.
+func trigramScore(queryTrigrams map[string]bool, text string) float64 {
+	if len(queryTrigrams) == 0 {
+		return 0
+	}
+	textTrigrams := trigrams(text)
+	shared := 0
+	for trigram := range queryTrigrams {
+		if textTrigrams[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(queryTrigrams))
+}
@@ -0,0 +1,93 @@
+package bookmarks
+
+import (
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestFuzzySearchBookmarks(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://kubernetes.io/docs", Title: "Kubernetes Documentation"},
+		{URL: "https://example.com/recipes", Title: "Weeknight Recipes"},
+		{URL: "https://go.dev/blog/generics", Title: "An Introduction To Generics"},
+		{URL: "https://k8s.example/kubernetes-networking", Title: "Cluster networking"},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// A typo, matched in a title and in a URL
+		{"kubernets", []string{"https://kubernetes.io/docs", "https://k8s.example/kubernetes-networking"}},
+		{"kubernets documentaton", []string{"https://kubernetes.io/docs"}},
+		{"introduction generic", []string{"https://go.dev/blog/generics"}},
+		{"zzyzx", nil},
+	}
+	for _, tt := range tests {
+		got, total, err := s.FuzzySearchBookmarks(ctx, tt.query, models.BookmarkFilter{}, 10, 0)
+		if err != nil {
+			t.Fatalf("FuzzySearchBookmarks(%q): %v", tt.query, err)
+		}
+		if total != len(tt.want) {
+			t.Errorf("FuzzySearchBookmarks(%q) total = %d, want %d", tt.query, total, len(tt.want))
+		}
+		var urls []string
+		for _, bookmark := range got {
+			urls = append(urls, bookmark.URL)
+		}
+		if len(urls) != len(tt.want) {
+			t.Errorf("FuzzySearchBookmarks(%q) = %v, want %v", tt.query, urls, tt.want)
+			continue
+		}
+		for i := range urls {
+			if urls[i] != tt.want[i] {
+				t.Errorf("FuzzySearchBookmarks(%q) = %v, want %v", tt.query, urls, tt.want)
+				break
+			}
+		}
+	}
+
+	// Out of range paging behaves like the SQL search
+	pages := []struct {
+		limit, offset, want int
+	}{
+		{10, -1, 2},
+		{-1, 0, 2},
+		{-1, 1, 1},
+		{0, 0, 0},
+	}
+	for _, p := range pages {
+		got, total, err := s.FuzzySearchBookmarks(ctx, "kubernets", models.BookmarkFilter{}, p.limit, p.offset)
+		if err != nil {
+			t.Fatalf("FuzzySearchBookmarks(limit %d, offset %d): %v", p.limit, p.offset, err)
+		}
+		if len(got) != p.want || total != 2 {
+			t.Errorf("FuzzySearchBookmarks(limit %d, offset %d) = %d of %d, want %d of 2", p.limit, p.offset, len(got), total, p.want)
+		}
+	}
+
+	if _, _, err := s.FuzzySearchBookmarks(ctx, " - ", models.BookmarkFilter{}, 10, 0); err == nil {
+		t.Error("FuzzySearchBookmarks with no words: want an error")
+	}
+}
+
+func TestTrigramScore(t *testing.T) {
+	query := trigrams("golang")
+	if got := trigramScore(query, "Golang weekly"); got != 1 {
+		t.Errorf("exact word: score = %v, want 1", got)
+	}
+	if got := trigramScore(query, "golnag"); got <= 0 || got >= fuzzyMinScore {
+		t.Errorf("transposed letters: score = %v, want between 0 and %v", got, fuzzyMinScore)
+	}
+	if got := trigramScore(query, ""); got != 0 {
+		t.Errorf("empty text: score = %v, want 0", got)
+	}
+}
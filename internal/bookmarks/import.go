@@ -3,6 +3,7 @@ package bookmarks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"golang.org/x/net/html"
 	"io"
@@ -16,27 +17,36 @@ import (
 	"github.com/schollz/progressbar/v3"
 )
 
-func (s *BookmarkService) ImportFromJSON(ctx context.Context, r io.Reader) (int, error) {
+func (s *BookmarkService) ImportFromJSON(ctx context.Context, r io.Reader) (*ImportResult, error) {
 	log.Println("Starting ImportFromJSON process")
-	numWorkers := ctx.Value("numWorkers").(int)
-	if numWorkers <= 0 {
-		numWorkers = 3
-	}
 
 	// Read JSON content from the reader
 	content, err := io.ReadAll(r)
 	if err != nil {
 		log.Printf("Error reading JSON content: %v", err)
-		return 0, fmt.Errorf("failed to read JSON content: %w", err)
+		return nil, fmt.Errorf("failed to read JSON content: %w", err)
 	}
 	log.Printf("Read %d bytes of JSON content", len(content))
 
+	// Exports from older goku versions are a flat array of bookmarks
+	if isLegacyJSON(content) {
+		log.Println("Detected legacy flat JSON export")
+		uniqueBookmarks, err := parseLegacyJSON(content)
+		if err != nil {
+			log.Printf("Error parsing legacy JSON: %v", err)
+			return nil, fmt.Errorf("failed to parse legacy JSON export: %w", err)
+		}
+		log.Printf("Found %d unique bookmarks to import", len(uniqueBookmarks))
+
+		return s.importBookmarks(ctx, uniqueBookmarks)
+	}
+
 	// Unmarshal the JSON data into a slice of BookmarkItem
 	var bookmarks []BookmarkItem
 	err = json.Unmarshal(content, &bookmarks)
 	if err != nil {
 		log.Printf("Error unmarshalling JSON: %v", err)
-		return 0, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 	log.Println("Successfully parsed JSON content")
 
@@ -45,8 +55,8 @@ func (s *BookmarkService) ImportFromJSON(ctx context.Context, r io.Reader) (int,
 	var uniqueBookmarks []*models.Bookmark
 
 	// First pass: extract unique bookmarks recursively from JSON
-	var extract func([]BookmarkItem)
-	extract = func(items []BookmarkItem) {
+	var extract func([]BookmarkItem, []string)
+	extract = func(items []BookmarkItem, folders []string) {
 		for _, item := range items {
 			if item.Type == "link" && item.URL != "" {
 				// Filter out duplicates
@@ -59,136 +69,150 @@ func (s *BookmarkService) ImportFromJSON(ctx context.Context, r io.Reader) (int,
 					if item.AddDate != 0 {
 						bookmark.CreatedAt = time.Unix(item.AddDate/1000, 0)
 					}
+					addFolderTags(ctx, bookmark, folders)
 					uniqueBookmarks = append(uniqueBookmarks, bookmark)
 				}
 			} else if item.Type == "folder" && len(item.Children) > 0 {
 				// Recursively process folder children
-				extract(item.Children)
+				extract(item.Children, append(folders[:len(folders):len(folders)], item.Title))
 			}
 		}
 	}
 
-	extract(bookmarks)
+	extract(bookmarks, nil)
 	log.Printf("Found %d unique bookmarks to import", len(uniqueBookmarks))
 
-	// Progress bar initialization
-	bar := progressbar.NewOptions(len(uniqueBookmarks),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetWidth(15),
-		progressbar.OptionSetDescription("[cyan][1/1][reset] Importing bookmarks..."),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
-	)
+	return s.importBookmarks(ctx, uniqueBookmarks)
+}
 
-	// Channel and sync structures for concurrent processing
-	bookmarkChan := make(chan *models.Bookmark, 100)
-	resultChan := make(chan error, 100)
-	var wg sync.WaitGroup
+// BookmarkItem is the struct used to unmarshal the JSON bookmark data
+type BookmarkItem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	URL      string         `json:"url,omitempty"`
+	AddDate  int64          `json:"addDate,omitempty"`
+	Icon     string         `json:"icon,omitempty"`
+	Children []BookmarkItem `json:"children,omitempty"`
+}
 
-	// Number of concurrent workers
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			for bookmark := range bookmarkChan {
-				if err := s.CreateBookmark(ctx, bookmark); err != nil {
-					resultChan <- fmt.Errorf("worker %d failed to import bookmark %s: %w", workerID, bookmark.URL, err)
-				} else {
-					resultChan <- nil
-					bar.Add(1) // Update progress bar
-				}
-			}
-		}(i)
-	}
+// legacyBookmark is a bookmark as written by the old ExportBookmarks, whose tags are
+// a single comma-separated string, or by ExportToJSON, which also writes the metadata
+// fields of models.Bookmark.
+type legacyBookmark struct {
+	URL          string     `json:"url"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Tags         legacyTags `json:"tags"`
+	CreatedAt    time.Time  `json:"created_at"`
+	Kind         string     `json:"kind"`
+	CanonicalURL string     `json:"canonical_url"`
+	Language     string     `json:"lang"`
+	FeedURL      string     `json:"feed_url"`
+	Favorite     bool       `json:"favorite"`
+	VisitCount   int        `json:"visit_count"`
+	LastVisited  *time.Time `json:"last_visited"`
+}
 
-	// Send bookmarks to worker goroutines
-	go func() {
-		for _, bookmark := range uniqueBookmarks {
-			bookmarkChan <- bookmark
-		}
-		close(bookmarkChan)
-	}()
+// legacyTags accepts tags either as a comma-separated string or as an array.
+type legacyTags []string
 
-	// Collect errors and wait for all workers to finish
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+func (t *legacyTags) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*t = list
+		return nil
+	}
 
-	// Process results and collect any errors
-	var errors []error
-	for err := range resultChan {
-		if err != nil {
-			errors = append(errors, err)
+	var joined string
+	if err := json.Unmarshal(data, &joined); err != nil {
+		return fmt.Errorf("tags must be a string or an array of strings: %w", err)
+	}
+	*t = nil
+	for _, tag := range strings.Split(joined, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*t = append(*t, tag)
 		}
 	}
+	return nil
+}
 
-	fmt.Println() // Add a newline after the progress bar
-
-	// Calculate number of successfully created bookmarks
-	recordsCreated := len(uniqueBookmarks) - len(errors)
-	log.Printf("Import summary: %d records created, %d errors", recordsCreated, len(errors))
-
-	// Log and return errors if any
-	if len(errors) > 0 {
-		for i, err := range errors {
-			log.Printf("Error %d: %v", i+1, err)
+// isLegacyJSON reports whether content is a flat array of bookmarks, as opposed to
+// the nested BookmarkItem format whose entries always carry a "type".
+func isLegacyJSON(content []byte) bool {
+	var items []struct {
+		Type *string `json:"type"`
+		URL  string  `json:"url"`
+	}
+	if err := json.Unmarshal(content, &items); err != nil || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if item.Type != nil || item.URL == "" {
+			return false
 		}
-		return recordsCreated, fmt.Errorf("encountered %d errors during import", len(errors))
 	}
+	return true
+}
 
-	// Verify import by counting records in the database
-	totalRecords, err := s.CountBookmarks(ctx)
-	if err != nil {
-		log.Printf("Error counting bookmarks after import: %v", err)
-		return recordsCreated, fmt.Errorf("failed to verify import: %w", err)
+// parseLegacyJSON converts a flat export into bookmarks, dropping repeated URLs.
+func parseLegacyJSON(content []byte) ([]*models.Bookmark, error) {
+	var items []legacyBookmark
+	if err := json.Unmarshal(content, &items); err != nil {
+		return nil, err
 	}
-	log.Printf("Total records in database after import: %d", totalRecords)
-
-	return recordsCreated, nil
-}
 
-// BookmarkItem is the struct used to unmarshal the JSON bookmark data
-type BookmarkItem struct {
-	Type     string         `json:"type"`
-	Title    string         `json:"title"`
-	URL      string         `json:"url,omitempty"`
-	AddDate  int64          `json:"addDate,omitempty"`
-	Icon     string         `json:"icon,omitempty"`
-	Children []BookmarkItem `json:"children,omitempty"`
+	uniqueURLs := make(map[string]struct{})
+	var bookmarks []*models.Bookmark
+	for _, item := range items {
+		if _, exists := uniqueURLs[item.URL]; exists {
+			continue
+		}
+		uniqueURLs[item.URL] = struct{}{}
+		bookmarks = append(bookmarks, &models.Bookmark{
+			URL:          item.URL,
+			Title:        item.Title,
+			Description:  item.Description,
+			Tags:         item.Tags,
+			CreatedAt:    item.CreatedAt,
+			Kind:         item.Kind,
+			CanonicalURL: item.CanonicalURL,
+			Language:     item.Language,
+			FeedURL:      item.FeedURL,
+			Favorite:     item.Favorite,
+			VisitCount:   item.VisitCount,
+			LastVisited:  item.LastVisited,
+		})
+	}
+	return bookmarks, nil
 }
 
-func (s *BookmarkService) ImportFromHTML(ctx context.Context, r io.Reader) (int, error) {
+func (s *BookmarkService) ImportFromHTML(ctx context.Context, r io.Reader) (*ImportResult, error) {
 	log.Println("Starting ImportFromHTML process")
 	content, err := io.ReadAll(r)
 	if err != nil {
 		log.Printf("Error reading HTML content: %v", err)
-		return 0, fmt.Errorf("failed to read HTML content: %w", err)
+		return nil, fmt.Errorf("failed to read HTML content: %w", err)
 	}
 	log.Printf("Read %d bytes of HTML content", len(content))
 
 	doc, err := html.Parse(strings.NewReader(string(content)))
 	if err != nil {
 		log.Printf("Error parsing HTML: %v", err)
-		return 0, fmt.Errorf("failed to parse HTML: %w", err)
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 	log.Println("Successfully parsed HTML content")
 
 	uniqueURLs := make(map[string]struct{})
 	var uniqueBookmarks []*models.Bookmark
 
-	// First pass: extract unique bookmarks
-	var extract func(*html.Node)
-	extract = func(n *html.Node) {
+	// First pass: extract unique bookmarks. A folder is an <H3> holding its name,
+	// followed by a <DL> listing its contents.
+	var extract func(*html.Node, []string)
+	extract = func(n *html.Node, folders []string) {
 		if n.Type == html.ElementNode && n.Data == "a" {
 			var url, title string
-			var addDate int64
+			var addDate, timeAdded int64
+			var tags []string
 
 			for _, attr := range n.Attr {
 				switch attr.Key {
@@ -196,8 +220,21 @@ func (s *BookmarkService) ImportFromHTML(ctx context.Context, r io.Reader) (int,
 					url = attr.Val
 				case "add_date":
 					addDate, _ = parseAddDate(attr.Val)
+				case "time_added":
+					// Pocket's name for add_date
+					timeAdded, _ = parseAddDate(attr.Val)
+				case "tags":
+					// Written by Pocket, Pinboard and Firefox, comma-separated
+					for _, tag := range strings.Split(attr.Val, ",") {
+						if tag = strings.TrimSpace(tag); tag != "" {
+							tags = append(tags, tag)
+						}
+					}
 				}
 			}
+			if addDate == 0 {
+				addDate = timeAdded
+			}
 
 			if n.FirstChild != nil {
 				title = n.FirstChild.Data
@@ -209,140 +246,186 @@ func (s *BookmarkService) ImportFromHTML(ctx context.Context, r io.Reader) (int,
 					bookmark := &models.Bookmark{
 						URL:   url,
 						Title: title,
+						Tags:  tags,
 					}
 					if addDate != 0 {
 						bookmark.CreatedAt = time.Unix(addDate, 0)
 					}
+					addFolderTags(ctx, bookmark, folders)
 					uniqueBookmarks = append(uniqueBookmarks, bookmark)
 				}
 			}
 		}
 
+		folder := ""
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			extract(c)
+			switch {
+			case c.Type == html.ElementNode && c.Data == "h3":
+				folder = nodeText(c)
+			case c.Type == html.ElementNode && c.Data == "dl" && folder != "":
+				extract(c, append(folders[:len(folders):len(folders)], folder))
+				folder = ""
+			default:
+				extract(c, folders)
+			}
 		}
 	}
 
-	extract(doc)
+	extract(doc, nil)
 	log.Printf("Found %d unique bookmarks to import", len(uniqueBookmarks))
 
-	bar := progressbar.NewOptions(len(uniqueBookmarks),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionShowCount(),
-		progressbar.OptionSetWidth(15),
-		progressbar.OptionSetDescription("[cyan][1/1][reset] Importing bookmarks..."),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "[green]=[reset]",
-			SaucerHead:    "[green]>[reset]",
-			SaucerPadding: " ",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}))
-
-	bookmarkChan := make(chan *models.Bookmark, 100)
-	resultChan := make(chan error, 100)
-	var wg sync.WaitGroup
+	return s.importBookmarks(ctx, uniqueBookmarks)
+}
 
-	// Start worker goroutines
-	const numWorkers = 5
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			for bookmark := range bookmarkChan {
-				err := s.CreateBookmark(ctx, bookmark)
-				if err != nil {
-					resultChan <- fmt.Errorf("worker %d failed to import bookmark %s: %w", workerID, bookmark.URL, err)
-				} else {
-					resultChan <- nil
-					bar.Add(1)
-				}
-			}
-		}(i)
+// addFolderTags tags bookmark with the names of the folders enclosing it, outermost
+// first, if the "foldersAsTags" context value is set.
+func addFolderTags(ctx context.Context, bookmark *models.Bookmark, folders []string) {
+	if foldersAsTags, _ := ctx.Value("foldersAsTags").(bool); !foldersAsTags {
+		return
+	}
+	for _, tag := range models.NormalizeTags(folders) {
+		bookmark.AddTag(tag)
 	}
+}
 
-	// Send bookmarks to workers
-	go func() {
-		for _, bookmark := range uniqueBookmarks {
-			bookmarkChan <- bookmark
+// nodeText returns the text inside n, with surrounding whitespace trimmed.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
 		}
-		close(bookmarkChan)
-	}()
-
-	// Collect results
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// Process results
-	var errors []error
-	for err := range resultChan {
-		if err != nil {
-			errors = append(errors, err)
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
 		}
 	}
+	collect(n)
+	return strings.TrimSpace(sb.String())
+}
 
-	fmt.Println() // Add a newline after the progress bar
+func (s *BookmarkService) ImportFromText(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	log.Println("Starting ImportFromText process")
 
-	recordsCreated := len(uniqueBookmarks) - len(errors)
-	log.Printf("Import summary: %d records created, %d errors", recordsCreated, len(errors))
+	urls, err := readURLList(r)
+	if err != nil {
+		log.Printf("Error reading text content: %v", err)
+		return nil, err
+	}
 
-	if len(errors) > 0 {
-		for i, err := range errors {
-			log.Printf("Error %d: %v", i+1, err)
+	uniqueBookmarks := make([]*models.Bookmark, len(urls))
+	for i, url := range urls {
+		uniqueBookmarks[i] = &models.Bookmark{
+			URL:       url,
+			Title:     "Imported from Text",
+			CreatedAt: time.Now(), // Set default timestamp
 		}
-		return recordsCreated, fmt.Errorf("encountered %d errors during import", len(errors))
 	}
 
-	// Verify the import by counting records in the database
-	totalRecords, err := s.CountBookmarks(ctx)
+	log.Printf("Found %d unique bookmarks to import", len(uniqueBookmarks))
+
+	return s.importBookmarks(ctx, uniqueBookmarks)
+}
+
+// AddFromList creates a bookmark for each URL in r, one per line, with the tags and
+// kind of template. Unlike ImportFromText it leaves titles empty, so fetching fills
+// them in. It honors the same "fetchData" and "numWorkers" context values.
+func (s *BookmarkService) AddFromList(ctx context.Context, r io.Reader, template models.Bookmark) (*ImportResult, error) {
+	urls, err := readURLList(r)
 	if err != nil {
-		log.Printf("Error counting bookmarks after import: %v", err)
-		return recordsCreated, fmt.Errorf("failed to verify import: %w", err)
+		return nil, err
 	}
-	log.Printf("Total records in database after import: %d", totalRecords)
 
-	return recordsCreated, nil
-}
+	bookmarks := make([]*models.Bookmark, len(urls))
+	for i, url := range urls {
+		bookmarks[i] = &models.Bookmark{
+			URL:  url,
+			Tags: append([]string(nil), template.Tags...),
+			Kind: template.Kind,
+		}
+	}
 
-func (s *BookmarkService) ImportFromText(ctx context.Context, r io.Reader) (int, error) {
-	log.Println("Starting ImportFromText process")
+	log.Printf("Adding %d bookmarks from a list", len(bookmarks))
+	return s.importBookmarks(ctx, bookmarks)
+}
 
-	// Read text content line by line
+// readURLList reads one URL per line from r, skipping blank lines and repeats.
+func readURLList(r io.Reader) ([]string, error) {
 	content, err := io.ReadAll(r)
 	if err != nil {
-		log.Printf("Error reading text content: %v", err)
-		return 0, fmt.Errorf("failed to read text content: %w", err)
+		return nil, fmt.Errorf("failed to read text content: %w", err)
 	}
-	lines := strings.Split(string(content), "\n")
-
-	uniqueURLs := make(map[string]struct{})
-	var uniqueBookmarks []*models.Bookmark
 
-	// Validate and deduplicate URLs
-	for _, line := range lines {
+	seen := make(map[string]struct{})
+	var urls []string
+	for _, line := range strings.Split(string(content), "\n") {
 		url := strings.TrimSpace(line)
 		if url == "" {
 			continue
 		}
+		if _, exists := seen[url]; exists {
+			continue
+		}
+		seen[url] = struct{}{}
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
 
-		if _, exists := uniqueURLs[url]; !exists {
-			uniqueURLs[url] = struct{}{}
+// ImportResult summarizes an import. Failed bookmarks are counted by category so a
+// run with many duplicates can be told apart from one with many network failures.
+type ImportResult struct {
+	Total       int     // unique bookmarks found in the input
+	Created     int     // bookmarks stored, including those counted in FetchFailed
+	FetchFailed int     // stored, but fetching their metadata failed
+	Duplicates  int     // skipped because the URL is already stored
+	InvalidURLs int     // rejected because the URL is empty or malformed
+	DBErrors    int     // the database refused to store them
+	Errors      []error // every failure except duplicates, for logging
+
+	SkippedFiles []string // archive entries that aren't bookmark exports
+}
 
-			bookmark := &models.Bookmark{
-				URL:       url,
-				Title:     "Imported from Text",
-				CreatedAt: time.Now(), // Set default timestamp
-			}
-			uniqueBookmarks = append(uniqueBookmarks, bookmark)
+// Failed returns the number of bookmarks that were not stored.
+func (r *ImportResult) Failed() int {
+	return r.Duplicates + r.InvalidURLs + r.DBErrors
+}
+
+// add records the outcome of importing a single bookmark.
+func (r *ImportResult) add(outcome importOutcome) {
+	switch {
+	case outcome.err == nil:
+		r.Created++
+		if outcome.fetchFailed {
+			r.FetchFailed++
 		}
+	case errors.Is(outcome.err, ErrDuplicateBookmark):
+		r.Duplicates++
+	case errors.Is(outcome.err, ErrInvalidURL):
+		r.InvalidURLs++
+		r.Errors = append(r.Errors, outcome.err)
+	default:
+		r.DBErrors++
+		r.Errors = append(r.Errors, outcome.err)
 	}
+}
 
-	log.Printf("Found %d unique bookmarks to import", len(uniqueBookmarks))
+type importOutcome struct {
+	fetchFailed bool
+	err         error
+}
+
+// importBookmarks creates bookmarks and tallies the outcome of each one. When the
+// "fetchData" context value is set, metadata is fetched by "numWorkers" concurrent
+// workers; otherwise bookmarks are stored in batches.
+func (s *BookmarkService) importBookmarks(ctx context.Context, bookmarks []*models.Bookmark) (*ImportResult, error) {
+	numWorkers, _ := ctx.Value("numWorkers").(int)
+	if numWorkers <= 0 {
+		numWorkers = 3
+	}
 
 	// Progress bar initialization
-	bar := progressbar.NewOptions(len(uniqueBookmarks),
+	bar := progressbar.NewOptions(len(bookmarks),
 		progressbar.OptionEnableColorCodes(true),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetWidth(15),
@@ -356,74 +439,110 @@ func (s *BookmarkService) ImportFromText(ctx context.Context, r io.Reader) (int,
 		}),
 	)
 
-	bookmarkChan := make(chan *models.Bookmark, 100)
-	resultChan := make(chan error, 100)
-	var wg sync.WaitGroup
+	var result *ImportResult
+	if fetchData, _ := ctx.Value("fetchData").(bool); fetchData {
+		result = s.importConcurrently(ctx, bookmarks, numWorkers, bar)
+	} else {
+		result = s.importInBatches(ctx, bookmarks, bar)
+	}
 
-	// Number of workers for concurrent processing
-	numWorkers := ctx.Value("numWorkers").(int)
-	if numWorkers <= 0 {
-		numWorkers = 3
+	fmt.Println() // Add a newline after the progress bar
+
+	if err := ctx.Err(); err != nil {
+		log.Printf("Import cancelled after %d of %d bookmarks", result.Created+result.Failed(), result.Total)
+		return result, fmt.Errorf("import cancelled: %w", err)
+	}
+
+	log.Printf("Import summary: %d created (%d without metadata), %d duplicates, %d invalid URLs, %d database errors",
+		result.Created, result.FetchFailed, result.Duplicates, result.InvalidURLs, result.DBErrors)
+	for i, err := range result.Errors {
+		log.Printf("Error %d: %v", i+1, err)
+	}
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("encountered %d errors during import", len(result.Errors))
+	}
+
+	// Verify import by counting records in the database
+	totalRecords, err := s.CountBookmarks(ctx)
+	if err != nil {
+		log.Printf("Error counting bookmarks after import: %v", err)
+		return result, fmt.Errorf("failed to verify import: %w", err)
 	}
+	log.Printf("Total records in database after import: %d", totalRecords)
+
+	return result, nil
+}
+
+// importConcurrently creates bookmarks one at a time on numWorkers goroutines,
+// fetching their metadata.
+func (s *BookmarkService) importConcurrently(ctx context.Context, bookmarks []*models.Bookmark, numWorkers int, bar *progressbar.ProgressBar) *ImportResult {
+	// Channel and sync structures for concurrent processing
+	bookmarkChan := make(chan *models.Bookmark, 100)
+	resultChan := make(chan importOutcome, 100)
+	var wg sync.WaitGroup
 
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
 			for bookmark := range bookmarkChan {
-				if err := s.CreateBookmark(ctx, bookmark); err != nil {
-					resultChan <- fmt.Errorf("worker %d failed to import bookmark %s: %w", workerID, bookmark.URL, err)
-				} else {
-					resultChan <- nil
-					bar.Add(1)
+				fetchFailed, err := s.createBookmark(ctx, bookmark)
+				if err != nil {
+					err = fmt.Errorf("worker %d failed to import bookmark %s: %w", workerID, bookmark.URL, err)
 				}
+				resultChan <- importOutcome{fetchFailed: fetchFailed, err: err}
+				bar.Add(1) // Update progress bar
 			}
 		}(i)
 	}
 
-	// Send bookmarks to worker goroutines
+	// Send bookmarks to worker goroutines until the import is cancelled
 	go func() {
-		for _, bookmark := range uniqueBookmarks {
-			bookmarkChan <- bookmark
+		defer close(bookmarkChan)
+		for _, bookmark := range bookmarks {
+			select {
+			case bookmarkChan <- bookmark:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(bookmarkChan)
 	}()
 
-	// Collect errors and wait for all workers to finish
+	// Wait for all workers to finish
 	go func() {
 		wg.Wait()
 		close(resultChan)
 	}()
 
-	// Process results
-	var errors []error
-	for err := range resultChan {
-		if err != nil {
-			errors = append(errors, err)
-		}
+	result := &ImportResult{Total: len(bookmarks)}
+	for outcome := range resultChan {
+		result.add(outcome)
 	}
+	return result
+}
 
-	fmt.Println() // Add a newline after the progress bar
-
-	recordsCreated := len(uniqueBookmarks) - len(errors)
-	log.Printf("Import summary: %d records created, %d errors", recordsCreated, len(errors))
-
-	if len(errors) > 0 {
-		for i, err := range errors {
-			log.Printf("Error %d: %v", i+1, err)
+// importBatchSize is how many bookmarks importInBatches stores per transaction.
+const importBatchSize = 500
+
+// importInBatches stores bookmarks without fetching metadata, importBatchSize at a
+// time through CreateBookmarks, so each batch costs one transaction instead of one
+// per bookmark.
+func (s *BookmarkService) importInBatches(ctx context.Context, bookmarks []*models.Bookmark, bar *progressbar.ProgressBar) *ImportResult {
+	result := &ImportResult{Total: len(bookmarks)}
+	for start := 0; start < len(bookmarks) && ctx.Err() == nil; start += importBatchSize {
+		batch := bookmarks[start:min(start+importBatchSize, len(bookmarks))]
+		batchResult, err := s.CreateBookmarks(ctx, batch)
+		result.Created += batchResult.Created
+		result.Duplicates += batchResult.Skipped
+		result.InvalidURLs += batchResult.Invalid
+		result.Errors = append(result.Errors, batchResult.InvalidErrors...)
+		if err != nil {
+			result.DBErrors += len(batch) - batchResult.Created - batchResult.Skipped - batchResult.Invalid
+			result.Errors = append(result.Errors, fmt.Errorf("failed to import batch of %d bookmarks: %w", len(batch), err))
 		}
-		return recordsCreated, fmt.Errorf("encountered %d errors during import", len(errors))
-	}
-
-	// Verify the import by counting records in the database
-	totalRecords, err := s.CountBookmarks(ctx)
-	if err != nil {
-		log.Printf("Error counting bookmarks after import: %v", err)
-		return recordsCreated, fmt.Errorf("failed to verify import: %w", err)
+		bar.Add(len(batch))
 	}
-	log.Printf("Total records in database after import: %d", totalRecords)
-
-	return recordsCreated, nil
+	return result
 }
 
 func (s *BookmarkService) CountBookmarks(ctx context.Context) (int, error) {
@@ -0,0 +1,142 @@
+package bookmarks
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+// importer is the signature shared by ImportFromJSON, ImportFromHTML, ImportFromText
+// and ImportFromCSV.
+type importer func(s *BookmarkService, ctx context.Context, r io.Reader) (*ImportResult, error)
+
+// importerFor picks the importer for a file by its extension, returning nil for files
+// that don't hold bookmarks.
+func importerFor(name string) importer {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return (*BookmarkService).ImportFromJSON
+	case ".html", ".htm":
+		return (*BookmarkService).ImportFromHTML
+	case ".txt":
+		return (*BookmarkService).ImportFromText
+	case ".csv":
+		return (*BookmarkService).ImportFromCSV
+	}
+	return nil
+}
+
+// isArchiveJunk reports whether an archive entry is metadata added by the tool that
+// built the archive, such as macOS resource forks.
+func isArchiveJunk(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".")
+}
+
+// merge adds the counts from a single file's import to r.
+func (r *ImportResult) merge(other *ImportResult) {
+	r.Total += other.Total
+	r.Created += other.Created
+	r.FetchFailed += other.FetchFailed
+	r.Duplicates += other.Duplicates
+	r.InvalidURLs += other.InvalidURLs
+	r.DBErrors += other.DBErrors
+	r.Errors = append(r.Errors, other.Errors...)
+}
+
+// importArchiveEntry imports a single file from an archive into result. Files that
+// aren't bookmark exports are recorded in result.SkippedFiles.
+func (s *BookmarkService) importArchiveEntry(ctx context.Context, name string, r io.Reader, result *ImportResult) {
+	if isArchiveJunk(name) {
+		return
+	}
+	importFile := importerFor(name)
+	if importFile == nil {
+		log.Printf("Skipping non-bookmark file in archive: %s", name)
+		result.SkippedFiles = append(result.SkippedFiles, name)
+		return
+	}
+
+	fmt.Printf("Importing %s\n", name)
+	fileResult, err := importFile(s, ctx, r)
+	if fileResult != nil {
+		result.merge(fileResult)
+		return
+	}
+	// The file could not be read or parsed at all
+	result.Errors = append(result.Errors, fmt.Errorf("%s: %w", name, err))
+}
+
+// archiveResult returns result with an error if the import was cancelled or any file
+// or bookmark failed.
+func archiveResult(ctx context.Context, result *ImportResult) (*ImportResult, error) {
+	if err := ctx.Err(); err != nil {
+		return result, fmt.Errorf("import cancelled: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("encountered %d errors during import", len(result.Errors))
+	}
+	return result, nil
+}
+
+// ImportFromZip imports every .html, .json, .txt and .csv file in a zip archive and
+// returns the combined result.
+func (s *BookmarkService) ImportFromZip(ctx context.Context, r io.ReaderAt, size int64) (*ImportResult, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	result := &ImportResult{}
+	for _, entry := range archive.File {
+		if ctx.Err() != nil {
+			break
+		}
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		file, err := entry.Open()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", entry.Name, err))
+			continue
+		}
+		s.importArchiveEntry(ctx, entry.Name, file, result)
+		file.Close()
+	}
+
+	return archiveResult(ctx, result)
+}
+
+// ImportFromTarGz imports every .html, .json, .txt and .csv file in a gzipped tar
+// archive and returns the combined result.
+func (s *BookmarkService) ImportFromTarGz(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	result := &ImportResult{}
+	archive := tar.NewReader(gz)
+	for ctx.Err() == nil {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		s.importArchiveEntry(ctx, header.Name, archive, result)
+	}
+
+	return archiveResult(ctx, result)
+}
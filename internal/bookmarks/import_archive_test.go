@@ -0,0 +1,74 @@
+package bookmarks
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+)
+
+// archiveFiles is the content of the archives built by the tests below.
+var archiveFiles = []struct{ name, body string }{
+	{"firefox/bookmarks.html", `<DL><DT><A HREF="https://html.example">HTML</A></DL>`},
+	{"chrome/bookmarks.json", `[{"url": "https://json.example", "title": "JSON", "tags": "a,b"}]`},
+	{"urls.txt", "https://text.example\nhttps://html.example\n"},
+	{"README.md", "not bookmarks"},
+	{"__MACOSX/._urls.txt", "resource fork"},
+}
+
+var wantArchiveResult = ImportResult{
+	Total:        4,
+	Created:      3,
+	Duplicates:   1,
+	SkippedFiles: []string{"README.md"},
+}
+
+func TestImportFromZip(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range archiveFiles {
+		f, err := w.Create(file.name)
+		if err != nil {
+			t.Fatalf("creating %s: %v", file.name, err)
+		}
+		f.Write([]byte(file.body))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+
+	s := newTestService(t)
+	result, err := s.ImportFromZip(testContext(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ImportFromZip: %v", err)
+	}
+	if !reflect.DeepEqual(*result, wantArchiveResult) {
+		t.Errorf("result = %+v, want %+v", *result, wantArchiveResult)
+	}
+}
+
+func TestImportFromTarGz(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, file := range archiveFiles {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.body)), Typeflag: tar.TypeReg}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatalf("writing header for %s: %v", file.name, err)
+		}
+		w.Write([]byte(file.body))
+	}
+	w.Close()
+	gz.Close()
+
+	s := newTestService(t)
+	result, err := s.ImportFromTarGz(testContext(), &buf)
+	if err != nil {
+		t.Fatalf("ImportFromTarGz: %v", err)
+	}
+	if !reflect.DeepEqual(*result, wantArchiveResult) {
+		t.Errorf("result = %+v, want %+v", *result, wantArchiveResult)
+	}
+}
@@ -0,0 +1,86 @@
+package bookmarks
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// ImportFromCSV imports bookmarks from CSV whose first row names the columns. A url
+// column is required; title, description and tags columns are optional, and other
+// columns are ignored, so files written by ExportToCSV can be imported as they are.
+// Tags are separated by semicolons.
+func (s *BookmarkService) ImportFromCSV(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	log.Println("Starting ImportFromCSV process")
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // rows with missing trailing cells are fine
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		// Spreadsheets often start UTF-8 files with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, fmt.Errorf("CSV header has no url column")
+	}
+	cell := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	uniqueURLs := make(map[string]struct{})
+	var uniqueBookmarks []*models.Bookmark
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+
+		url := cell(record, "url")
+		if url == "" {
+			continue
+		}
+		if _, exists := uniqueURLs[url]; exists {
+			continue
+		}
+		uniqueURLs[url] = struct{}{}
+
+		var tags []string
+		for _, tag := range strings.Split(cell(record, "tags"), ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		uniqueBookmarks = append(uniqueBookmarks, &models.Bookmark{
+			URL:         url,
+			Title:       cell(record, "title"),
+			Description: cell(record, "description"),
+			Tags:        tags,
+		})
+	}
+
+	log.Printf("Found %d unique bookmarks to import", len(uniqueBookmarks))
+
+	return s.importBookmarks(ctx, uniqueBookmarks)
+}
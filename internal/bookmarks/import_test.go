@@ -0,0 +1,283 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fallrising/goku-cli/internal/database"
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// newTestService returns a service backed by a fresh SQLite database, without DuckDB.
+func newTestService(t *testing.T) *BookmarkService {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := database.NewDatabase(filepath.Join(dir, "test.db"), filepath.Join(dir, "test_cache.db"), "test", database.DefaultConnectionOptions)
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	s := NewBookmarkService(db, nil)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// testContext returns the context values the import and create paths expect, with
+// fetching disabled.
+func testContext() context.Context {
+	ctx := context.WithValue(context.Background(), "numWorkers", 2)
+	return context.WithValue(ctx, "fetchData", false)
+}
+
+func TestImportFromTextCategorizesFailures(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	if err := s.CreateBookmark(ctx, &models.Bookmark{URL: "https://existing.example", Title: "Existing"}); err != nil {
+		t.Fatalf("CreateBookmark: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"https://new.example",
+		"https://existing.example",
+		"https://",
+		"https://new.example", // repeated within the file, dropped before import
+	}, "\n")
+
+	result, err := s.ImportFromText(ctx, strings.NewReader(input))
+	if err == nil {
+		t.Error("ImportFromText: expected an error for the invalid URL")
+	}
+	if result == nil {
+		t.Fatal("ImportFromText returned no result")
+	}
+
+	want := ImportResult{Total: 3, Created: 1, Duplicates: 1, InvalidURLs: 1}
+	got := *result
+	got.Errors = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddFromList(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	input := "https://a.example\n\n  https://b.example  \nhttps://a.example\nhttps://\n"
+	result, err := s.AddFromList(ctx, strings.NewReader(input), models.Bookmark{Tags: []string{"Reading"}, Kind: "page"})
+	if err == nil {
+		t.Error("AddFromList: expected an error for the invalid URL")
+	}
+	if result == nil || result.Total != 3 || result.Created != 2 || result.InvalidURLs != 1 {
+		t.Fatalf("result = %+v, want 2 of 3 created and 1 invalid", result)
+	}
+
+	for _, url := range []string{"https://a.example", "https://b.example"} {
+		bookmark, err := s.repo.GetByURL(ctx, url)
+		if err != nil || bookmark == nil {
+			t.Fatalf("GetByURL(%s) = %v, %v", url, bookmark, err)
+		}
+		if bookmark.Title != "" || bookmark.Kind != "page" || !reflect.DeepEqual(bookmark.Tags, []string{"reading"}) {
+			t.Errorf("%s: title %q, kind %q, tags %v; want no title, page, [reading]", url, bookmark.Title, bookmark.Kind, bookmark.Tags)
+		}
+	}
+}
+
+func TestImportFromTextInBatches(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	if err := s.CreateBookmark(ctx, &models.Bookmark{URL: "https://example.com/7", Title: "Existing"}); err != nil {
+		t.Fatalf("CreateBookmark: %v", err)
+	}
+
+	// Enough URLs to span three batches, one of them already stored
+	var lines []string
+	for i := 0; i < 2*importBatchSize+3; i++ {
+		lines = append(lines, fmt.Sprintf("https://example.com/%d", i))
+	}
+
+	result, err := s.ImportFromText(ctx, strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatalf("ImportFromText: %v", err)
+	}
+	want := ImportResult{Total: len(lines), Created: len(lines) - 1, Duplicates: 1}
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+	if count, err := s.CountBookmarks(ctx); err != nil || count != len(lines) {
+		t.Errorf("CountBookmarks = %d, %v; want %d", count, err, len(lines))
+	}
+}
+
+func TestParseLegacyJSON(t *testing.T) {
+	legacy := `[
+		{"id": 1, "url": "https://a.example", "title": "A", "description": "first", "tags": "go, web", "created_at": "2023-04-05T06:07:08Z"},
+		{"id": 2, "url": "https://b.example", "title": "B", "tags": ["x"]},
+		{"id": 3, "url": "https://a.example", "title": "A again", "tags": ""}
+	]`
+	if !isLegacyJSON([]byte(legacy)) {
+		t.Fatal("isLegacyJSON = false for a flat export")
+	}
+
+	bookmarks, err := parseLegacyJSON([]byte(legacy))
+	if err != nil {
+		t.Fatalf("parseLegacyJSON: %v", err)
+	}
+	if len(bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2", len(bookmarks))
+	}
+	if got := bookmarks[0].Tags; !reflect.DeepEqual(got, []string{"go", "web"}) {
+		t.Errorf("string tags = %v, want [go web]", got)
+	}
+	if got := bookmarks[1].Tags; !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("array tags = %v, want [x]", got)
+	}
+	if bookmarks[0].Description != "first" || bookmarks[0].CreatedAt.Year() != 2023 {
+		t.Errorf("first bookmark = %+v, want description and created_at carried over", bookmarks[0])
+	}
+}
+
+func TestIsLegacyJSONRejectsNestedFormat(t *testing.T) {
+	nested := `[{"type": "folder", "title": "Bar", "children": [{"type": "link", "url": "https://a.example"}]}]`
+	if isLegacyJSON([]byte(nested)) {
+		t.Error("isLegacyJSON = true for the nested BookmarkItem format")
+	}
+}
+
+func TestImportCancelled(t *testing.T) {
+	s := newTestService(t)
+	ctx, cancel := context.WithCancel(testContext())
+	cancel()
+
+	result, err := s.ImportFromText(ctx, strings.NewReader("https://a.example\nhttps://b.example\n"))
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("ImportFromText error = %v, want a cancellation", err)
+	}
+	if result != nil && result.Created != 0 {
+		t.Errorf("created %d bookmarks after cancellation", result.Created)
+	}
+}
+
+func TestImportFromCSV(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	input := "\ufeffTitle,URL,Tags,notes\n" +
+		`"Go, the language",https://go.dev,go; lang ,ignored` + "\n" +
+		"No tags,https://example.com\n" +
+		",,,\n" +
+		"Repeat,https://go.dev,,\n"
+	result, err := s.ImportFromCSV(ctx, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportFromCSV: %v", err)
+	}
+	if result.Total != 2 || result.Created != 2 {
+		t.Fatalf("result = %+v, want 2 unique bookmarks created", result)
+	}
+
+	bookmark, err := s.repo.GetByURL(ctx, "https://go.dev")
+	if err != nil || bookmark == nil {
+		t.Fatalf("GetByURL = %v, %v", bookmark, err)
+	}
+	if bookmark.Title != "Go, the language" || !reflect.DeepEqual(bookmark.Tags, []string{"go", "lang"}) {
+		t.Errorf("imported %q with tags %v, want the quoted title and [go lang]", bookmark.Title, bookmark.Tags)
+	}
+
+	if _, err := s.ImportFromCSV(ctx, strings.NewReader("title,tags\nA,x\n")); err == nil {
+		t.Error("ImportFromCSV accepted a header without a url column")
+	}
+}
+
+func TestImportFromHTMLReadsTags(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	// As exported by Pocket
+	input := `<!DOCTYPE html>
+<html><body><h1>Unread</h1><ul>
+<li><a href="https://a.example" time_added="1600000000" tags="go,reading list">A</a></li>
+<li><a href="https://b.example" time_added="1600000001" tags="">B</a></li>
+</ul></body></html>`
+	result, err := s.ImportFromHTML(ctx, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportFromHTML: %v", err)
+	}
+	if result.Created != 2 {
+		t.Fatalf("created %d bookmarks, want 2", result.Created)
+	}
+
+	bookmark, err := s.repo.GetByURL(ctx, "https://a.example")
+	if err != nil || bookmark == nil {
+		t.Fatalf("GetByURL = %v, %v", bookmark, err)
+	}
+	if want := []string{"go", "reading list"}; !reflect.DeepEqual(bookmark.Tags, want) {
+		t.Errorf("tags = %v, want %v", bookmark.Tags, want)
+	}
+	if want := time.Unix(1600000000, 0); !bookmark.CreatedAt.Equal(want) {
+		t.Errorf("created at = %v, want %v", bookmark.CreatedAt, want)
+	}
+}
+
+func TestImportFoldersAsTags(t *testing.T) {
+	netscape := `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1600000000">Dev</H3>
+    <DL><p>
+        <DT><H3>Go</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev">Go</A>
+        </DL><p>
+        <DT><A HREF="https://dev.example">Dev</A>
+    </DL><p>
+    <DT><A HREF="https://top.example">Top</A>
+</DL><p>`
+	nested := `[{"type": "folder", "title": "Dev", "children": [
+		{"type": "folder", "title": "Go", "children": [{"type": "link", "title": "Go", "url": "https://go.dev"}]},
+		{"type": "link", "title": "Dev", "url": "https://dev.example"}
+	]}, {"type": "link", "title": "Top", "url": "https://top.example"}]`
+
+	want := map[string][]string{
+		"https://go.dev":      {"dev", "go"},
+		"https://dev.example": {"dev"},
+		"https://top.example": nil,
+	}
+	imports := map[string]importer{netscape: (*BookmarkService).ImportFromHTML, nested: (*BookmarkService).ImportFromJSON}
+	for input, importFrom := range imports {
+		for _, foldersAsTags := range []bool{true, false} {
+			s := newTestService(t)
+			ctx := context.WithValue(testContext(), "foldersAsTags", foldersAsTags)
+			if _, err := importFrom(s, ctx, strings.NewReader(input)); err != nil {
+				t.Fatalf("import: %v", err)
+			}
+			for url, tags := range want {
+				bookmark, err := s.repo.GetByURL(ctx, url)
+				if err != nil || bookmark == nil {
+					t.Fatalf("GetByURL(%s) = %v, %v", url, bookmark, err)
+				}
+				var got []string
+				for _, tag := range bookmark.Tags {
+					if tag != "" {
+						got = append(got, tag)
+					}
+				}
+				if !foldersAsTags {
+					tags = nil
+				}
+				if !reflect.DeepEqual(got, tags) {
+					t.Errorf("foldersAsTags=%v: %s tagged %v, want %v", foldersAsTags, url, got, tags)
+				}
+			}
+		}
+	}
+}
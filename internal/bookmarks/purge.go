@@ -4,8 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+
+	"github.com/fallrising/goku-cli/pkg/models"
 )
 
+// PreviewPurge reports how many bookmarks a purge would delete along with up to
+// sampleSize of them, without deleting anything.
+func (s *BookmarkService) PreviewPurge(ctx context.Context, sampleSize int) (int, []*models.Bookmark, error) {
+	count, err := s.CountBookmarks(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	sample, err := s.ListBookmarks(ctx, sampleSize, 0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+
+	return count, sample, nil
+}
+
 func (s *BookmarkService) PurgeBookmarks(ctx context.Context) error {
 	log.Println("Starting PurgeBookmarks process")
 
@@ -4,14 +4,21 @@ import (
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/pkg/models"
+	"strings"
 )
 
 func (s *BookmarkService) SearchBookmarks(ctx context.Context, query string, limit, offset int) ([]*models.Bookmark, error) {
-	if query == "" {
+	return s.SearchBookmarksFiltered(ctx, query, models.BookmarkFilter{}, limit, offset)
+}
+
+func (s *BookmarkService) SearchBookmarksFiltered(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, error) {
+	query, filter.Tags = splitTagTerms(query, filter.Tags)
+	if query == "" && len(filter.Tags) == 0 {
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
 
-	bookmarks, err := s.repo.Search(ctx, query, limit, offset)
+	filter.StripWWW = s.stripWWW
+	bookmarks, err := s.repo.SearchFiltered(ctx, query, filter, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search bookmarks: %w", err)
 	}
@@ -22,3 +29,35 @@ func (s *BookmarkService) SearchBookmarks(ctx context.Context, query string, lim
 
 	return bookmarks, nil
 }
+
+// SearchBookmarksWithCount returns a page of the bookmarks matching query and filter
+// and how many match in all. "tag:name" terms in query are added to the tags of
+// filter; a query of only such terms lists the bookmarks with those tags.
+func (s *BookmarkService) SearchBookmarksWithCount(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error) {
+	query, filter.Tags = splitTagTerms(query, filter.Tags)
+	if query == "" && len(filter.Tags) == 0 {
+		return nil, 0, fmt.Errorf("search query cannot be empty")
+	}
+
+	filter.StripWWW = s.stripWWW
+	bookmarks, total, err := s.repo.SearchWithCount(ctx, query, filter, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search bookmarks: %w", err)
+	}
+	return bookmarks, total, nil
+}
+
+// splitTagTerms moves the "tag:name" terms of query to tags, returning the rest of the
+// query and the extended tags.
+func splitTagTerms(query string, tags []string) (string, []string) {
+	tags = tags[:len(tags):len(tags)] // never append into the caller's array
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if len(word) > len("tag:") && strings.EqualFold(word[:len("tag:")], "tag:") {
+			tags = append(tags, word[len("tag:"):])
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), tags
+}
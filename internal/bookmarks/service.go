@@ -4,9 +4,11 @@ package bookmarks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/fallrising/goku-cli/internal/database"
 	"log"
+	"net/url"
 	"strings"
 
 	"github.com/fallrising/goku-cli/internal/fetcher"
@@ -17,57 +19,211 @@ import (
 type BookmarkService struct {
 	repo        interfaces.BookmarkRepository
 	duckDBStats *database.DuckDBStats
+	fetcher     *fetcher.Fetcher
+	stripWWW    bool
+	wayback     bool
+
+	trackingParams []string
 }
 
 func NewBookmarkService(repo interfaces.BookmarkRepository, duckDBStats *database.DuckDBStats) *BookmarkService {
-	return &BookmarkService{repo: repo, duckDBStats: duckDBStats}
+	return &BookmarkService{
+		repo:        repo,
+		duckDBStats: duckDBStats,
+		fetcher:     fetcher.NewFetcher(fetcher.DefaultFetchConfig()),
+
+		trackingParams: models.DefaultTrackingParams,
+	}
+}
+
+// SetFetchConfig replaces the configuration used to fetch page metadata.
+func (s *BookmarkService) SetFetchConfig(config fetcher.FetchConfig) {
+	s.fetcher = fetcher.NewFetcher(config)
+}
+
+// Close releases the bookmark repository and the statistics database.
+func (s *BookmarkService) Close() error {
+	repoErr := s.repo.Close()
+	var statsErr error
+	if s.duckDBStats != nil {
+		statsErr = s.duckDBStats.Close()
+	}
+	if repoErr != nil {
+		return fmt.Errorf("failed to close repository: %w", repoErr)
+	}
+	if statsErr != nil {
+		return fmt.Errorf("failed to close DuckDB: %w", statsErr)
+	}
+	return nil
+}
+
+// AddTrackingParams adds query parameters for CreateBookmark to strip from URLs on top
+// of models.DefaultTrackingParams. A trailing "*" matches a prefix, e.g. "share_*".
+func (s *BookmarkService) AddTrackingParams(params ...string) {
+	s.trackingParams = append(append([]string(nil), s.trackingParams...), params...)
+}
+
+// archivedTag marks bookmarks whose metadata was read from a Wayback Machine snapshot.
+const archivedTag = "archived"
+
+// SetWaybackFallback makes metadata fetches for pages that are unreachable or gone
+// fall back to the closest Wayback Machine snapshot. Bookmarks described from a
+// snapshot are tagged "archived".
+func (s *BookmarkService) SetWaybackFallback(enabled bool) {
+	s.wayback = enabled
+}
+
+// SetStripWWW makes duplicate checks and host filters treat www.example.com and
+// example.com as the same host. Stored URLs are left as the user entered them.
+func (s *BookmarkService) SetStripWWW(stripWWW bool) {
+	s.stripWWW = stripWWW
+}
+
+// findExisting looks up a stored bookmark equivalent to any of urls under the
+// service's normalization rules. It returns nil when there is none.
+func (s *BookmarkService) findExisting(ctx context.Context, urls ...string) (*models.Bookmark, error) {
+	for _, url := range urls {
+		for _, candidate := range models.EquivalentURLs(url, s.stripWWW) {
+			existing, err := s.repo.GetByURL(ctx, candidate)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return existing, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Errors returned by CreateBookmark and RefreshMetadata that callers may want to
+// tell apart.
+var (
+	ErrDuplicateBookmark = errors.New("bookmark with this URL already exists")
+	ErrInvalidURL        = errors.New("invalid URL")
+	ErrPageGone          = errors.New("page no longer exists")
+)
+
+// fetchMetadata fetches a page's metadata, falling back to the Wayback Machine when
+// that is enabled and the fetcher suggests trying another source. Failures are
+// reported in FetchError.
+func (s *BookmarkService) fetchMetadata(ctx context.Context, url string) *fetcher.PageContent {
+	return s.fetchMetadataIfChanged(ctx, url, fetcher.Validators{})
+}
+
+// fetchMetadataIfChanged is fetchMetadata made conditional on validators. An unchanged
+// page comes back with NotModified set.
+func (s *BookmarkService) fetchMetadataIfChanged(ctx context.Context, url string, validators fetcher.Validators) *fetcher.PageContent {
+	content, retry, err := s.fetcher.FetchPageContentIfChanged(ctx, url, validators)
+	if content == nil {
+		content = &fetcher.PageContent{FetchError: fmt.Sprintf("%v", err)}
+	}
+	if retry && s.wayback && content.Failed() {
+		log.Printf("Warning: failed to fetch page content: %s, will try Wayback Machine", content.FetchError)
+		archived, _ := fetcher.FetchMetadataFromWaybackMachineCtx(ctx, url)
+		if archived.Failed() {
+			log.Printf("Warning: failed to fetch metadata from Wayback Machine: %s", archived.FetchError)
+		} else {
+			archived.Kind = content.Kind
+			content = archived
+		}
+	}
+	return content
 }
 
 func (s *BookmarkService) CreateBookmark(ctx context.Context, bookmark *models.Bookmark) error {
+	_, err := s.createBookmark(ctx, bookmark)
+	return err
+}
+
+// createBookmark stores bookmark, fetching missing metadata when enabled. fetchFailed
+// reports that the bookmark was stored even though fetching its metadata failed.
+func (s *BookmarkService) createBookmark(ctx context.Context, bookmark *models.Bookmark) (fetchFailed bool, err error) {
 	log.Printf("CreateBookmark called with URL: %s", bookmark.URL)
 
 	if bookmark.URL == "" {
 		log.Println("Error: URL is required")
-		return fmt.Errorf("URL is required")
+		return false, fmt.Errorf("%w: URL is required", ErrInvalidURL)
+	}
+
+	// Check if URL starts with "http://" or "https://". This happens before the
+	// duplicate check so "example.com" is compared as "https://example.com".
+	if !(strings.HasPrefix(bookmark.URL, "http://") || strings.HasPrefix(bookmark.URL, "https://")) {
+		bookmark.URL = "https://" + bookmark.URL
+		log.Printf("URL updated to: %s", bookmark.URL)
+	}
+
+	if parsed, parseErr := url.Parse(bookmark.URL); parseErr != nil || parsed.Host == "" {
+		log.Printf("Error: invalid URL: %s", bookmark.URL)
+		return false, fmt.Errorf("%w: %s", ErrInvalidURL, bookmark.URL)
+	}
+
+	// Tracking parameters would keep the same page from being seen as a duplicate.
+	// The URL as given is still checked, as bookmarks stored before cleaning keep it.
+	givenURL := bookmark.URL
+	bookmark.URL = models.CleanURL(bookmark.URL, s.trackingParams)
+	if bookmark.URL != givenURL {
+		log.Printf("URL cleaned to: %s", bookmark.URL)
 	}
 
 	// Check if URL already exists in the database
-	existingBookmark, err := s.repo.GetByURL(ctx, bookmark.URL)
+	existingBookmark, err := s.findExisting(ctx, bookmark.URL, givenURL)
 	if err != nil {
 		log.Printf("Error checking for existing bookmark: %v", err)
-		return fmt.Errorf("failed to check for existing bookmark: %w", err)
+		return false, fmt.Errorf("failed to check for existing bookmark: %w", err)
 	}
 	if existingBookmark != nil {
 		log.Printf("Bookmark already exists with URL: %s", existingBookmark.URL)
-		return fmt.Errorf("bookmark with this URL already exists: %s", existingBookmark.URL)
-	}
-
-	// Check if URL starts with "http://" or "https://"
-	if !(strings.HasPrefix(bookmark.URL, "http://") || strings.HasPrefix(bookmark.URL, "https://")) {
-		bookmark.URL = "https://" + bookmark.URL
-		log.Printf("URL updated to: %s", bookmark.URL)
+		return false, fmt.Errorf("%w: %s", ErrDuplicateBookmark, existingBookmark.URL)
 	}
 
 	// Fetch page content if title, description, or tags are not provided
 	if bookmark.Title == "" || bookmark.Description == "" || len(bookmark.Tags) == 0 {
 		log.Println("Fetching page content for metadata")
 		var content *fetcher.PageContent
-		var retry bool
 		fetchData := ctx.Value("fetchData").(bool)
 		if fetchData {
-			content, retry, err = fetcher.FetchPageContent(bookmark.URL)
-			if err != nil && retry {
-				log.Printf("Warning: failed to fetch page content: %v, will try Wayback Machine", err)
-				content, err = fetcher.FetchMetadataFromWaybackMachine(bookmark.URL)
-				if err != nil {
-					log.Printf("Warning: failed to fetch metadata from Wayback Machine: %v", err)
-				}
-			}
+			content = s.fetchMetadata(ctx, bookmark.URL)
 		}
 		// Update bookmark with fetched content
 		if content != nil {
-			if content.FetchError != "" {
+			if bookmark.Kind == "" {
+				bookmark.Kind = content.Kind
+			}
+			if content.CanonicalURL != "" && content.CanonicalURL != bookmark.URL {
+				// Two URLs for the same article share a canonical form
+				existing, err := s.findExisting(ctx, content.CanonicalURL)
+				if err != nil {
+					log.Printf("Error checking for existing bookmark: %v", err)
+					return false, fmt.Errorf("failed to check for existing bookmark: %w", err)
+				}
+				if existing != nil {
+					log.Printf("Bookmark already exists with canonical URL: %s", existing.URL)
+					return false, fmt.Errorf("%w: %s", ErrDuplicateBookmark, existing.URL)
+				}
+			}
+			if bookmark.CanonicalURL == "" {
+				bookmark.CanonicalURL = content.CanonicalURL
+			}
+			if bookmark.FaviconURL == "" {
+				bookmark.FaviconURL = content.FaviconURL
+			}
+			if bookmark.ImageURL == "" {
+				bookmark.ImageURL = content.ImageURL
+			}
+			if bookmark.Language == "" {
+				bookmark.Language = content.Language
+			}
+			if bookmark.FeedURL == "" {
+				bookmark.FeedURL = content.FeedURL
+			}
+			bookmark.ReadingTimeMinutes = content.ReadingTimeMinutes
+			if content.Gone() {
+				log.Printf("Warning: %s is gone (HTTP %d)", bookmark.URL, content.HTTPStatus)
+			}
+			if content.Failed() {
 				log.Printf("Warning: %s", content.FetchError)
+				fetchFailed = true
 				bookmark.Description = fmt.Sprintf("Metadata fetch failed: %s", content.FetchError)
 			} else {
 				if bookmark.Title == "" || strings.HasPrefix(bookmark.Title, "http://") || strings.HasPrefix(bookmark.Title, "https://") {
@@ -82,19 +238,100 @@ func (s *BookmarkService) CreateBookmark(ctx context.Context, bookmark *models.B
 					bookmark.Tags = content.Tags
 					log.Printf("Tags set from fetched content: %v", bookmark.Tags)
 				}
+				if content.Archived {
+					bookmark.AddTag(archivedTag)
+				}
 			}
 		}
 	}
 
+	// A kind given by the user wins; otherwise fall back to what the URL alone says
+	bookmark.Kind = strings.ToLower(strings.TrimSpace(bookmark.Kind))
+	if bookmark.Kind == "" {
+		bookmark.Kind = fetcher.InferKind(bookmark.URL, "")
+	}
+
+	// Tags from flags, importers and fetched keywords all arrive as given; store them
+	// in canonical form so "Go" and "go" are one tag
+	bookmark.Tags = models.NormalizeTags(bookmark.Tags)
+
 	log.Printf("Attempting to create bookmark in repository: %+v", bookmark)
 	err = s.repo.Create(ctx, bookmark)
+	if errors.Is(err, database.ErrDuplicateURL) {
+		// Another import worker stored the same URL after our duplicate check
+		return false, fmt.Errorf("%w: %s", ErrDuplicateBookmark, bookmark.URL)
+	}
 	if err != nil {
 		log.Printf("Error creating bookmark in repository: %v", err)
-		return fmt.Errorf("failed to create bookmark in repository: %w", err)
+		return false, fmt.Errorf("failed to create bookmark in repository: %w", err)
 	}
 
 	log.Printf("Bookmark successfully created with ID: %d", bookmark.ID)
-	return nil
+	return fetchFailed, nil
+}
+
+// BatchResult reports what happened to each bookmark passed to CreateBookmarks.
+type BatchResult struct {
+	Created int // inserted into the database
+	Skipped int // already stored under an equivalent URL
+	Invalid int // rejected before reaching the database: empty or malformed URLs
+
+	InvalidErrors []error // why each bookmark counted in Invalid was rejected
+}
+
+// reject counts a bookmark turned away for err.
+func (r *BatchResult) reject(err error) {
+	r.Invalid++
+	r.InvalidErrors = append(r.InvalidErrors, err)
+}
+
+// CreateBookmarks stores bookmarks in one batch without fetching metadata. URLs that
+// already exist are skipped rather than failing the batch.
+func (s *BookmarkService) CreateBookmarks(ctx context.Context, bookmarks []*models.Bookmark) (*BatchResult, error) {
+	result := &BatchResult{}
+	var valid []*models.Bookmark
+	for _, bookmark := range bookmarks {
+		if strings.TrimSpace(bookmark.URL) == "" {
+			result.reject(fmt.Errorf("%w: URL is required", ErrInvalidURL))
+			continue
+		}
+		if !(strings.HasPrefix(bookmark.URL, "http://") || strings.HasPrefix(bookmark.URL, "https://")) {
+			bookmark.URL = "https://" + bookmark.URL
+		}
+		if parsed, err := url.Parse(bookmark.URL); err != nil || parsed.Host == "" {
+			result.reject(fmt.Errorf("%w: %s", ErrInvalidURL, bookmark.URL))
+			continue
+		}
+		givenURL := bookmark.URL
+		bookmark.URL = models.CleanURL(bookmark.URL, s.trackingParams)
+
+		if bookmark.Kind == "" {
+			bookmark.Kind = fetcher.InferKind(bookmark.URL, "")
+		}
+		bookmark.Tags = models.NormalizeTags(bookmark.Tags)
+
+		// CreateBatch only skips exact URL matches, so equivalent forms are filtered here
+		existing, err := s.findExisting(ctx, bookmark.URL, givenURL)
+		if err != nil {
+			return result, fmt.Errorf("failed to check for existing bookmark: %w", err)
+		}
+		if existing != nil {
+			result.Skipped++
+			continue
+		}
+		valid = append(valid, bookmark)
+	}
+
+	created, err := s.repo.CreateBatch(ctx, valid)
+	result.Created = created
+	if err != nil {
+		log.Printf("Error creating bookmark batch in repository: %v", err)
+		return result, fmt.Errorf("failed to create bookmarks in repository: %w", err)
+	}
+	result.Skipped += len(valid) - created
+
+	log.Printf("Batch create summary: %d created, %d skipped, %d invalid", result.Created, result.Skipped, result.Invalid)
+	return result, nil
 }
 
 func (s *BookmarkService) GetBookmark(ctx context.Context, id int64) (*models.Bookmark, error) {
@@ -118,28 +355,21 @@ func (s *BookmarkService) UpdateBookmark(ctx context.Context, updatedBookmark *m
 	// Check if the URL has changed
 	if updatedBookmark.URL != existingBookmark.URL {
 		// Check for duplicates
-		duplicate, err := s.repo.GetByURL(ctx, updatedBookmark.URL)
+		duplicate, err := s.findExisting(ctx, updatedBookmark.URL)
 		if err != nil {
 			return fmt.Errorf("failed to check for duplicate URL: %w", err)
 		}
-		if duplicate != nil {
+		if duplicate != nil && duplicate.ID != existingBookmark.ID {
 			return fmt.Errorf("another bookmark with URL '%s' already exists", updatedBookmark.URL)
 		}
 
 		fetchData := ctx.Value("fetchData").(bool)
-		content := &fetcher.PageContent{}
-		retry := false
 		if fetchData {
 			// Fetch new metadata for the new URL
-			content, retry, err = fetcher.FetchPageContent(updatedBookmark.URL)
-			if err != nil && retry {
-				log.Printf("Warning: failed to fetch page content: %v, will try Wayback Machine", err)
-				content, err = fetcher.FetchMetadataFromWaybackMachine(updatedBookmark.URL)
-				if err != nil {
-					log.Printf("Warning: failed to fetch metadata from Wayback Machine: %v", err)
-				}
-			}
-			if content.FetchError != "" {
+			content := s.fetchMetadata(ctx, updatedBookmark.URL)
+			updatedBookmark.Kind = content.Kind
+			updatedBookmark.Language = content.Language
+			if content.Failed() {
 				fmt.Printf("Warning: %s\n", content.FetchError)
 				updatedBookmark.Description = fmt.Sprintf("Metadata fetch failed: %s", content.FetchError)
 			} else {
@@ -147,12 +377,16 @@ func (s *BookmarkService) UpdateBookmark(ctx context.Context, updatedBookmark *m
 				updatedBookmark.Title = content.Title
 				updatedBookmark.Description = content.Description
 				updatedBookmark.Tags = content.Tags
+				if content.Archived {
+					updatedBookmark.AddTag(archivedTag)
+				}
 			}
 		}
 	}
 
 	// Track changes
 	updated := false
+	updatedBookmark.Tags = models.NormalizeTags(updatedBookmark.Tags)
 
 	if updatedBookmark.URL != "" && updatedBookmark.URL != existingBookmark.URL {
 		existingBookmark.URL = updatedBookmark.URL
@@ -170,6 +404,14 @@ func (s *BookmarkService) UpdateBookmark(ctx context.Context, updatedBookmark *m
 		existingBookmark.Tags = updatedBookmark.Tags
 		updated = true
 	}
+	if updatedBookmark.Kind != "" && updatedBookmark.Kind != existingBookmark.Kind {
+		existingBookmark.Kind = updatedBookmark.Kind
+		updated = true
+	}
+	if updatedBookmark.Language != "" && updatedBookmark.Language != existingBookmark.Language {
+		existingBookmark.Language = updatedBookmark.Language
+		updated = true
+	}
 
 	// Update only if necessary
 	if updated {
@@ -180,6 +422,112 @@ func (s *BookmarkService) UpdateBookmark(ctx context.Context, updatedBookmark *m
 	return nil
 }
 
+// RefreshMetadata fetches bookmark's page again and stores the fetched title,
+// description, tags and kind. A failed fetch is recorded in the description, as when
+// the bookmark was added.
+func (s *BookmarkService) RefreshMetadata(ctx context.Context, bookmark *models.Bookmark) error {
+	_, err := s.refreshMetadata(ctx, bookmark, false)
+	return err
+}
+
+// RefreshMetadataIfChanged is RefreshMetadata sending the ETag and Last-Modified
+// values from the bookmark's previous fetch. It reports false, leaving the bookmark
+// untouched, if the server says the page has not changed since.
+func (s *BookmarkService) RefreshMetadataIfChanged(ctx context.Context, bookmark *models.Bookmark) (bool, error) {
+	return s.refreshMetadata(ctx, bookmark, true)
+}
+
+// refreshMetadata refetches and stores a bookmark's metadata, conditionally if
+// ifChanged is set, and records the page's validators for the next conditional fetch.
+func (s *BookmarkService) refreshMetadata(ctx context.Context, bookmark *models.Bookmark, ifChanged bool) (bool, error) {
+	var validators fetcher.Validators
+	if ifChanged {
+		etag, lastModified, err := s.repo.GetFetchValidators(ctx, bookmark.URL)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		validators = fetcher.Validators{ETag: etag, LastModified: lastModified}
+	}
+
+	content := s.fetchMetadataIfChanged(ctx, bookmark.URL, validators)
+	if content.NotModified {
+		return false, nil
+	}
+	if content.Kind != "" {
+		bookmark.Kind = content.Kind
+	}
+	if content.CanonicalURL != "" {
+		bookmark.CanonicalURL = content.CanonicalURL
+	}
+	if content.FaviconURL != "" {
+		bookmark.FaviconURL = content.FaviconURL
+	}
+	if content.ImageURL != "" {
+		bookmark.ImageURL = content.ImageURL
+	}
+	if content.Language != "" {
+		bookmark.Language = content.Language
+	}
+	if content.FeedURL != "" {
+		bookmark.FeedURL = content.FeedURL
+	}
+	if content.ReadingTimeMinutes > 0 {
+		bookmark.ReadingTimeMinutes = content.ReadingTimeMinutes
+	}
+	if content.Failed() {
+		log.Printf("Warning: %s", content.FetchError)
+		bookmark.Description = fmt.Sprintf("Metadata fetch failed: %s", content.FetchError)
+	} else {
+		bookmark.Title = content.Title
+		bookmark.Description = content.Description
+		if len(content.Tags) > 0 {
+			bookmark.Tags = models.NormalizeTags(content.Tags)
+		}
+		if content.Archived {
+			bookmark.AddTag(archivedTag)
+		}
+	}
+
+	if err := s.repo.Update(ctx, bookmark); err != nil {
+		return false, fmt.Errorf("failed to update bookmark: %w", err)
+	}
+	// A failed fetch clears the validators so the next conditional fetch retries it
+	v := content.Validators
+	if err := s.repo.SetFetchValidators(ctx, bookmark.URL, v.ETag, v.LastModified); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if content.Failed() && content.Gone() {
+		return true, fmt.Errorf("%w: HTTP %d", ErrPageGone, content.HTTPStatus)
+	}
+	if content.Failed() {
+		return true, fmt.Errorf("failed to fetch metadata: %s", content.FetchError)
+	}
+	return true, nil
+}
+
+// SetFavorite marks the bookmark with the given ID as a favorite, or unmarks it.
+func (s *BookmarkService) SetFavorite(ctx context.Context, id int64, favorite bool) error {
+	bookmark, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to fetch bookmark: %w", err)
+	}
+	if bookmark == nil {
+		return fmt.Errorf("bookmark not found with ID: %d", id)
+	}
+	if bookmark.Favorite == favorite {
+		return nil
+	}
+	bookmark.Favorite = favorite
+	return s.repo.Update(ctx, bookmark)
+}
+
+// RecordVisit counts a visit to the bookmark with the given ID.
+func (s *BookmarkService) RecordVisit(ctx context.Context, id int64) error {
+	return s.repo.RecordVisit(ctx, id)
+}
+
+// DeleteBookmark moves a bookmark to the trash, from which RestoreBookmark can bring
+// it back until the trash is emptied.
 func (s *BookmarkService) DeleteBookmark(ctx context.Context, id int64) error {
 	return s.repo.Delete(ctx, id)
 }
@@ -188,13 +536,57 @@ func (s *BookmarkService) ListBookmarks(ctx context.Context, limit, offset int)
 	return s.repo.List(ctx, limit, offset)
 }
 
-// Helper function to check if tags are equal
+// listAllBookmarks returns every bookmark, reading them a page at a time.
+func (s *BookmarkService) listAllBookmarks(ctx context.Context) ([]*models.Bookmark, error) {
+	const pageSize = 100
+
+	var all []*models.Bookmark
+	for offset := 0; ; offset += pageSize {
+		page, err := s.ListBookmarks(ctx, pageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+		}
+		if len(page) == 0 {
+			return all, nil
+		}
+		all = append(all, page...)
+	}
+}
+
+func (s *BookmarkService) ListBookmarksFiltered(ctx context.Context, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, error) {
+	filter.StripWWW = s.stripWWW
+	return s.repo.ListFiltered(ctx, filter, limit, offset)
+}
+
+// ListBookmarksWithCount returns a page of the bookmarks matching filter and how
+// many match in all, e.g. for "showing 10 of 347".
+func (s *BookmarkService) ListBookmarksWithCount(ctx context.Context, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error) {
+	filter.StripWWW = s.stripWWW
+	return s.repo.ListWithCount(ctx, filter, limit, offset)
+}
+
+// CountBookmarksFiltered returns how many bookmarks match filter.
+func (s *BookmarkService) CountBookmarksFiltered(ctx context.Context, filter models.BookmarkFilter) (int, error) {
+	_, total, err := s.ListBookmarksWithCount(ctx, filter, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+	return total, nil
+}
+
+// equalTags reports whether tags1 and tags2 hold the same tags once normalized, in
+// any order and ignoring repeats, so that only a real change of tags is written.
 func equalTags(tags1, tags2 []string) bool {
-	if len(tags1) != len(tags2) {
+	set1, set2 := models.NormalizeTags(tags1), models.NormalizeTags(tags2)
+	if len(set1) != len(set2) {
 		return false
 	}
-	for i, tag := range tags1 {
-		if tag != tags2[i] {
+	seen := make(map[string]struct{}, len(set1))
+	for _, tag := range set1 {
+		seen[tag] = struct{}{}
+	}
+	for _, tag := range set2 {
+		if _, ok := seen[tag]; !ok {
 			return false
 		}
 	}
@@ -0,0 +1,336 @@
+package bookmarks
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestCreateBookmarkCleansURL(t *testing.T) {
+	s := newTestService(t)
+	s.AddTrackingParams("ref")
+	ctx := testContext()
+
+	bookmark := &models.Bookmark{URL: "https://Example.com:443/post/?utm_source=feed&id=3&ref=hn", Title: "Post"}
+	if err := s.CreateBookmark(ctx, bookmark); err != nil {
+		t.Fatalf("CreateBookmark: %v", err)
+	}
+	if want := "https://example.com/post?id=3"; bookmark.URL != want {
+		t.Errorf("stored URL = %q, want %q", bookmark.URL, want)
+	}
+
+	err := s.CreateBookmark(ctx, &models.Bookmark{URL: "https://example.com/post?id=3&fbclid=xyz", Title: "Again"})
+	if !errors.Is(err, ErrDuplicateBookmark) {
+		t.Errorf("same page with another tracking param: err = %v, want ErrDuplicateBookmark", err)
+	}
+
+	// Stored before cleaning existed, under the URL exactly as given
+	legacy := &models.Bookmark{URL: "https://example.com/old/?utm_medium=email", Title: "Old"}
+	if err := s.repo.Create(ctx, legacy); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	err = s.CreateBookmark(ctx, &models.Bookmark{URL: legacy.URL, Title: "Old again"})
+	if !errors.Is(err, ErrDuplicateBookmark) {
+		t.Errorf("URL stored uncleaned: err = %v, want ErrDuplicateBookmark", err)
+	}
+}
+
+func TestCreateBookmarkFindsEquivalentURLs(t *testing.T) {
+	s := newTestService(t)
+	s.SetStripWWW(true)
+	ctx := testContext()
+
+	bookmark := &models.Bookmark{URL: "https://site.com/page/?b=2&a=1", Title: "Page"}
+	if err := s.CreateBookmark(ctx, bookmark); err != nil {
+		t.Fatalf("CreateBookmark: %v", err)
+	}
+	if want := "https://site.com/page?a=1&b=2"; bookmark.URL != want {
+		t.Errorf("stored URL = %q, want %q", bookmark.URL, want)
+	}
+
+	for _, url := range []string{
+		"https://site.com/page?a=1&b=2",
+		"https://site.com:443/page/?b=2&a=1",
+		"https://WWW.Site.com/page?a=1&b=2",
+		"site.com/page/?a=1&b=2",
+	} {
+		err := s.CreateBookmark(ctx, &models.Bookmark{URL: url, Title: "Again"})
+		if !errors.Is(err, ErrDuplicateBookmark) {
+			t.Errorf("CreateBookmark(%q): err = %v, want ErrDuplicateBookmark", url, err)
+		}
+	}
+}
+
+func TestEqualTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags1     []string
+		tags2     []string
+		wantEqual bool
+	}{
+		{"same order", []string{"a", "b"}, []string{"a", "b"}, true},
+		{"reordered", []string{"a", "b"}, []string{"b", "a"}, true},
+		{"repeated tag", []string{"a", "a", "b"}, []string{"b", "a"}, true},
+		{"case and spacing", []string{"Go", " web  dev"}, []string{"go", "web dev"}, true},
+		{"both empty", nil, []string{""}, true},
+		{"tag added", []string{"a"}, []string{"a", "b"}, false},
+		{"tag replaced", []string{"a", "b"}, []string{"a", "c"}, false},
+		{"same length, different repeats", []string{"a", "a", "b"}, []string{"a", "b", "b", "c"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalTags(tt.tags1, tt.tags2); got != tt.wantEqual {
+				t.Errorf("equalTags(%q, %q) = %v, want %v", tt.tags1, tt.tags2, got, tt.wantEqual)
+			}
+			if got := equalTags(tt.tags2, tt.tags1); got != tt.wantEqual {
+				t.Errorf("equalTags(%q, %q) = %v, want %v", tt.tags2, tt.tags1, got, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestUpdateBookmarkIgnoresTagOrder(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	bookmark := &models.Bookmark{URL: "https://example.com/tagged", Title: "Tagged", Tags: []string{"a", "b"}}
+	if err := s.repo.Create(ctx, bookmark); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := s.UpdateBookmark(ctx, &models.Bookmark{ID: bookmark.ID, Tags: []string{"B", "a", "a"}}); err != nil {
+		t.Fatalf("UpdateBookmark: %v", err)
+	}
+	got, err := s.GetBookmark(ctx, bookmark.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark: %v", err)
+	}
+	// Left as stored: a reordering alone is not written
+	if !reflect.DeepEqual(got.Tags, []string{"a", "b"}) {
+		t.Errorf("tags = %v, want [a b]", got.Tags)
+	}
+}
+
+func TestTagsNormalizedOnWrite(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+	want := []string{"go", "web dev"}
+
+	created := &models.Bookmark{URL: "https://example.com/created", Title: "Created", Tags: []string{"Go", " go ", "Web  Dev"}}
+	if err := s.CreateBookmark(ctx, created); err != nil {
+		t.Fatalf("CreateBookmark: %v", err)
+	}
+	imported := &models.Bookmark{URL: "https://example.com/imported", Title: "Imported", Tags: []string{"GO", "web dev,go"}}
+	if _, err := s.CreateBookmarks(ctx, []*models.Bookmark{imported}); err != nil {
+		t.Fatalf("CreateBookmarks: %v", err)
+	}
+	updated := &models.Bookmark{URL: "https://example.com/updated", Title: "Updated"}
+	if err := s.repo.Create(ctx, updated); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := s.UpdateBookmark(ctx, &models.Bookmark{ID: updated.ID, Tags: []string{"Go", "WEB DEV"}}); err != nil {
+		t.Fatalf("UpdateBookmark: %v", err)
+	}
+
+	for _, bookmark := range []*models.Bookmark{created, imported, updated} {
+		stored, err := s.repo.GetByURL(ctx, bookmark.URL)
+		if err != nil || stored == nil {
+			t.Fatalf("GetByURL(%s) = %v, %v", bookmark.URL, stored, err)
+		}
+		if !reflect.DeepEqual(stored.Tags, want) {
+			t.Errorf("%s: tags = %q, want %q", bookmark.URL, stored.Tags, want)
+		}
+	}
+
+	tags, err := s.ListAllTags(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTags: %v", err)
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("ListAllTags = %q, want %q", tags, want)
+	}
+}
+
+func TestSetFavorite(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	starred := &models.Bookmark{URL: "https://example.com/starred", Title: "Starred"}
+	other := &models.Bookmark{URL: "https://example.com/other", Title: "Other"}
+	for _, bookmark := range []*models.Bookmark{starred, other} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	if err := s.SetFavorite(ctx, starred.ID, true); err != nil {
+		t.Fatalf("SetFavorite: %v", err)
+	}
+	if err := s.SetFavorite(ctx, 9999, true); err == nil {
+		t.Errorf("SetFavorite on a missing bookmark succeeded")
+	}
+
+	// Updating other fields keeps the flag
+	if err := s.UpdateBookmark(ctx, &models.Bookmark{ID: starred.ID, URL: starred.URL, Title: "Renamed"}); err != nil {
+		t.Fatalf("UpdateBookmark: %v", err)
+	}
+	favorites, total, err := s.ListBookmarksWithCount(ctx, models.BookmarkFilter{Favorites: true}, 10, 0)
+	if err != nil {
+		t.Fatalf("ListBookmarksWithCount: %v", err)
+	}
+	if total != 1 || len(favorites) != 1 || favorites[0].ID != starred.ID || !favorites[0].Favorite || favorites[0].Title != "Renamed" {
+		t.Fatalf("favorites = %v (total %d), want only the renamed bookmark %d", favorites, total, starred.ID)
+	}
+
+	if err := s.SetFavorite(ctx, starred.ID, false); err != nil {
+		t.Fatalf("SetFavorite: %v", err)
+	}
+	if _, total, _ := s.ListBookmarksWithCount(ctx, models.BookmarkFilter{Favorites: true}, 10, 0); total != 0 {
+		t.Errorf("%d favorites after unfavorite, want 0", total)
+	}
+}
+
+func TestAddTagToBookmark(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	bookmark := &models.Bookmark{URL: "https://example.com", Title: "Example", Description: "kept", Tags: []string{"web"}}
+	if err := s.repo.Create(ctx, bookmark); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := s.AddTagToBookmark(ctx, bookmark.ID, "Go", "web", " reference "); err != nil {
+		t.Fatalf("AddTagToBookmark: %v", err)
+	}
+	got, err := s.GetBookmark(ctx, bookmark.ID)
+	if err != nil {
+		t.Fatalf("GetBookmark: %v", err)
+	}
+	if want := []string{"web", "go", "reference"}; !reflect.DeepEqual(got.Tags, want) {
+		t.Errorf("tags = %v, want %v", got.Tags, want)
+	}
+	if got.Title != "Example" || got.Description != "kept" {
+		t.Errorf("other fields changed: %+v", got)
+	}
+
+	if err := s.AddTagToBookmark(ctx, 9999, "go"); err == nil {
+		t.Error("AddTagToBookmark on a missing bookmark succeeded")
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	misspelled := &models.Bookmark{URL: "https://a.example", Title: "A", Tags: []string{"progamming", "go"}}
+	both := &models.Bookmark{URL: "https://b.example", Title: "B", Tags: []string{"programming", "progamming"}}
+	other := &models.Bookmark{URL: "https://c.example", Title: "C", Tags: []string{"go"}}
+	for _, bookmark := range []*models.Bookmark{misspelled, both, other} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	renamed, err := s.RenameTag(ctx, "progamming", "programming")
+	if err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if renamed != 2 {
+		t.Errorf("renamed on %d bookmarks, want 2", renamed)
+	}
+	for id, want := range map[int64][]string{
+		misspelled.ID: {"programming", "go"},
+		both.ID:       {"programming"},
+		other.ID:      {"go"},
+	} {
+		got, err := s.GetBookmark(ctx, id)
+		if err != nil {
+			t.Fatalf("GetBookmark(%d): %v", id, err)
+		}
+		if !reflect.DeepEqual(got.Tags, want) {
+			t.Errorf("bookmark %d tags = %v, want %v", id, got.Tags, want)
+		}
+	}
+
+	if _, err := s.RenameTag(ctx, "go", "Go"); err == nil {
+		t.Error("renaming a tag to itself succeeded")
+	}
+}
+
+func TestSearchTagTerms(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://a.example", Title: "Generics in Go", Tags: []string{"programming", "go"}},
+		{URL: "https://b.example", Title: "Generics in Java", Tags: []string{"programming"}},
+		{URL: "https://c.example", Title: "tag:literal generics", Tags: []string{"misc"}},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	tests := []struct {
+		query  string
+		filter models.BookmarkFilter
+		want   []string
+	}{
+		{"tag:programming", models.BookmarkFilter{}, []string{"https://a.example", "https://b.example"}},
+		{"generics TAG:go", models.BookmarkFilter{}, []string{"https://a.example"}},
+		{"tag:go tag:misc", models.BookmarkFilter{AnyTags: true}, []string{"https://a.example", "https://c.example"}},
+		{"java", models.BookmarkFilter{Tags: []string{"programming"}}, []string{"https://b.example"}},
+	}
+	for _, tt := range tests {
+		bookmarks, total, err := s.SearchBookmarksWithCount(ctx, tt.query, tt.filter, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchBookmarksWithCount(%q): %v", tt.query, err)
+		}
+		var got []string
+		for _, bookmark := range bookmarks {
+			got = append(got, bookmark.URL)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) || total != len(tt.want) {
+			t.Errorf("search %q = %v (total %d), want %v", tt.query, got, total, tt.want)
+		}
+	}
+
+	if _, _, err := s.SearchBookmarksWithCount(ctx, "  ", models.BookmarkFilter{}, 10, 0); err == nil {
+		t.Error("empty query succeeded")
+	}
+}
+
+func TestCountBookmarksFiltered(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://github.com/a", Title: "A", Tags: []string{"go"}},
+		{URL: "https://github.com/b", Title: "B", Tags: []string{"rust"}},
+		{URL: "https://go.dev", Title: "Go", Tags: []string{"go"}},
+	} {
+		if err := s.repo.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	tests := []struct {
+		filter models.BookmarkFilter
+		want   int
+	}{
+		{models.BookmarkFilter{Tags: []string{"go"}}, 2},
+		{models.BookmarkFilter{Host: "github.com"}, 2},
+		{models.BookmarkFilter{Tags: []string{"go"}, Host: "github.com"}, 1},
+		{models.BookmarkFilter{Tags: []string{"python"}}, 0},
+	}
+	for _, tt := range tests {
+		if got, err := s.CountBookmarksFiltered(ctx, tt.filter); err != nil || got != tt.want {
+			t.Errorf("CountBookmarksFiltered(%+v) = %d, %v; want %d", tt.filter, got, err, tt.want)
+		}
+	}
+}
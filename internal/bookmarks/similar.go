@@ -0,0 +1,170 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// DefaultSimilarityThreshold is the similarity FindSimilarBookmarks requires when the
+// caller has no preference.
+const DefaultSimilarityThreshold = 0.9
+
+// SimilarGroup is a cluster of bookmarks that look like the same page: each one's
+// normalized URL or title resembles that of another bookmark in the group. Bookmarks
+// are in ID order.
+type SimilarGroup struct {
+	Bookmarks []*models.Bookmark
+}
+
+// FindSimilarBookmarks clusters bookmarks whose normalized URLs or titles have a
+// trigram similarity of at least threshold, from 0 (exclusive) to 1. URLs are
+// normalized by dropping the scheme, "www.", tracking parameters, the fragment and
+// trailing slashes and by sorting the query, and are only compared within the same
+// host. Clusters are returned for review; nothing is changed.
+func (s *BookmarkService) FindSimilarBookmarks(ctx context.Context, threshold float64) ([]SimilarGroup, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("similarity threshold must be greater than 0 and at most 1, got %g", threshold)
+	}
+
+	all, err := s.listAllBookmarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urlTrigrams := make([]map[string]bool, len(all))
+	titleTrigrams := make([]map[string]bool, len(all))
+	byHost := make(map[string][]int)
+	var hosts []string
+	for i, bookmark := range all {
+		host, key := s.similarityURL(bookmark.URL)
+		urlTrigrams[i] = trigrams(key)
+		titleTrigrams[i] = trigrams(bookmark.Title)
+		if _, seen := byHost[host]; !seen {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], i)
+	}
+
+	clusters := newUnionFind(len(all))
+	for _, host := range hosts {
+		members := byHost[host]
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				if trigramSimilarity(urlTrigrams[members[a]], urlTrigrams[members[b]]) >= threshold {
+					clusters.union(members[a], members[b])
+				}
+			}
+		}
+	}
+
+	// Two sets can only be threshold-similar if the smaller has at least threshold
+	// times as many trigrams as the larger, so titles are compared in size order and
+	// each one only against the titles within that ratio
+	var titled []int
+	for i, set := range titleTrigrams {
+		if len(set) > 0 {
+			titled = append(titled, i)
+		}
+	}
+	sort.SliceStable(titled, func(a, b int) bool { return len(titleTrigrams[titled[a]]) < len(titleTrigrams[titled[b]]) })
+	for a := 0; a < len(titled); a++ {
+		smaller := float64(len(titleTrigrams[titled[a]]))
+		for b := a + 1; b < len(titled); b++ {
+			if smaller < threshold*float64(len(titleTrigrams[titled[b]])) {
+				break
+			}
+			if trigramSimilarity(titleTrigrams[titled[a]], titleTrigrams[titled[b]]) >= threshold {
+				clusters.union(titled[a], titled[b])
+			}
+		}
+	}
+
+	// Bookmarks are listed by ID, so groups come out ordered by their oldest bookmark
+	groupOf := make(map[int]int)
+	var groups []SimilarGroup
+	for i, bookmark := range all {
+		root := clusters.find(i)
+		index, ok := groupOf[root]
+		if !ok {
+			index = len(groups)
+			groupOf[root] = index
+			groups = append(groups, SimilarGroup{})
+		}
+		groups[index].Bookmarks = append(groups[index].Bookmarks, bookmark)
+	}
+
+	var similar []SimilarGroup
+	for _, group := range groups {
+		if len(group.Bookmarks) > 1 {
+			similar = append(similar, group)
+		}
+	}
+	return similar, nil
+}
+
+// similarityURL returns the host of raw without "www." and the form of raw compared by
+// FindSimilarBookmarks. The host keeps a non-default port, since CleanURL has already
+// dropped default ones. URLs that cannot be parsed are compared as they are, under an
+// empty host.
+func (s *BookmarkService) similarityURL(raw string) (host, key string) {
+	u, err := url.Parse(models.CleanURL(raw, s.trackingParams))
+	if err != nil || u.Host == "" {
+		return "", strings.ToLower(raw)
+	}
+	host = strings.TrimPrefix(u.Host, "www.")
+	key = host + u.EscapedPath()
+	if query := u.Query(); len(query) > 0 {
+		// Encode sorts by name, so parameter order doesn't count
+		key += "?" + query.Encode()
+	}
+	return host, key
+}
+
+// trigramSimilarity returns the Jaccard similarity of two trigram sets: the share of
+// all their trigrams that both contain, from 0 to 1.
+func trigramSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range a {
+		if b[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// unionFind tracks which of n items have been joined into the same cluster.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+// find returns the representative of i's cluster.
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+// union joins the clusters of i and j.
+func (u *unionFind) union(i, j int) {
+	if root, other := u.find(i), u.find(j); root != other {
+		u.parent[other] = root
+	}
+}
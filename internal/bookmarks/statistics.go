@@ -2,16 +2,119 @@ package bookmarks
 
 import (
 	"context"
+	"encoding/csv"
+	"fmt"
 	"github.com/fallrising/goku-cli/internal/database"
 	"github.com/fallrising/goku-cli/pkg/models"
+	"io"
+	"strconv"
+	"time"
 )
 
-func (s *BookmarkService) GetStatistics(ctx context.Context) (*models.Statistics, error) {
-	// Use DuckDB for statistics
-	return s.duckDBStats.GetStatistics(ctx)
+// GetStatistics reports on the library from DuckDB, listing the top hostnames and
+// daily creation counts for the last days days. It reads what the last sync copied.
+func (s *BookmarkService) GetStatistics(ctx context.Context, days, top int) (*models.Statistics, error) {
+	return statisticsFrom(ctx, s.duckDBStats, days, top)
+}
+
+// Analyze syncs the bookmarks into DuckDB and reports on them, as sync followed by
+// GetStatistics would. With a duckDBPath it uses that DuckDB file instead of the
+// service's own.
+func (s *BookmarkService) Analyze(ctx context.Context, duckDBPath string, days, top int) (*models.Statistics, error) {
+	duckDBStats := s.duckDBStats
+	if duckDBPath != "" {
+		var err error
+		duckDBStats, err = database.NewDuckDBStats(duckDBPath)
+		if err != nil {
+			return nil, err
+		}
+		defer duckDBStats.Close()
+		if err := duckDBStats.Init(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := duckDBStats.SyncFromSQLite(s.repo.(*database.Database)); err != nil {
+		return nil, fmt.Errorf("failed to sync data to DuckDB: %w", err)
+	}
+	return statisticsFrom(ctx, duckDBStats, days, top)
+}
+
+// statisticsFrom validates the report limits and reads the statistics from duckDBStats.
+func statisticsFrom(ctx context.Context, duckDBStats *database.DuckDBStats, days, top int) (*models.Statistics, error) {
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive, got %d", days)
+	}
+	if top <= 0 {
+		return nil, fmt.Errorf("top must be positive, got %d", top)
+	}
+	return duckDBStats.GetStatistics(ctx, days, top)
 }
 
 // Add a method to sync data from SQLite to DuckDB
 func (s *BookmarkService) SyncToDuckDB() error {
 	return s.duckDBStats.SyncFromSQLite(s.repo.(*database.Database))
 }
+
+// GetCountSummary returns library totals straight from SQLite, without the DuckDB
+// sync that GetStatistics relies on.
+func (s *BookmarkService) GetCountSummary(ctx context.Context) (*models.CountSummary, error) {
+	total, err := s.repo.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	accessibility, err := s.repo.CountAccessibility(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count accessibility: %w", err)
+	}
+
+	tagCounts, err := s.repo.CountByTag(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+
+	hostnames, err := s.repo.ListUniqueHostnames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hostnames: %w", err)
+	}
+
+	return &models.CountSummary{
+		Total:        total,
+		Accessible:   accessibility["accessible"],
+		Inaccessible: accessibility["inaccessible"],
+		Tags:         len(tagCounts),
+		Hosts:        len(hostnames),
+	}, nil
+}
+
+// ExportGrowthCSV writes one "date,count" row for each of the last n days including
+// today, oldest first. Days without new bookmarks are written with a zero count so
+// the series charts cleanly.
+func (s *BookmarkService) ExportGrowthCSV(ctx context.Context, w io.Writer, days int) error {
+	if days <= 0 {
+		return fmt.Errorf("days must be greater than zero")
+	}
+
+	// CountCreatedLastNDays(n) covers today plus the n previous days
+	counts, err := s.repo.CountCreatedLastNDays(ctx, days-1)
+	if err != nil {
+		return fmt.Errorf("failed to count bookmarks by day: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"date", "count"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	today := time.Now().UTC()
+	for i := days - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i).Format("2006-01-02")
+		if err := writer.Write([]string{day, strconv.Itoa(counts[day])}); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,32 @@
+package bookmarks
+
+import (
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestGetCountSummary(t *testing.T) {
+	s := newTestService(t)
+	ctx := testContext()
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://a.example/1", Title: "a1", Tags: []string{"go", "web"}},
+		{URL: "https://a.example/2", Title: "a2", Tags: []string{"go"}},
+		{URL: "https://b.example", Title: "b", Description: "Metadata fetch failed: timeout"},
+	} {
+		if err := s.CreateBookmark(ctx, bookmark); err != nil {
+			t.Fatalf("CreateBookmark(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	summary, err := s.GetCountSummary(ctx)
+	if err != nil {
+		t.Fatalf("GetCountSummary: %v", err)
+	}
+
+	want := models.CountSummary{Total: 3, Accessible: 2, Inaccessible: 1, Tags: 2, Hosts: 2}
+	if *summary != want {
+		t.Errorf("summary = %+v, want %+v", *summary, want)
+	}
+}
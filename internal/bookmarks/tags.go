@@ -3,8 +3,35 @@ package bookmarks
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
 )
 
+// AddTagToBookmark adds each of tagsToAdd that the bookmark doesn't already carry,
+// leaving its other fields as they are. Tags are normalized with models.NormalizeTag.
+func (s *BookmarkService) AddTagToBookmark(ctx context.Context, bookmarkID int64, tagsToAdd ...string) error {
+	bookmark, err := s.repo.GetByID(ctx, bookmarkID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch bookmark: %w", err)
+	}
+
+	before := len(bookmark.Tags)
+	for _, tag := range tagsToAdd {
+		bookmark.AddTag(tag)
+	}
+	if len(bookmark.Tags) == before {
+		return nil
+	}
+
+	err = s.repo.Update(ctx, bookmark)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark after adding tags: %w", err)
+	}
+
+	return nil
+}
+
 func (s *BookmarkService) RemoveTagFromBookmark(ctx context.Context, bookmarkID int64, tagToRemove string) error {
 	// Fetch the existing bookmark
 	bookmark, err := s.repo.GetByID(ctx, bookmarkID)
@@ -31,3 +58,59 @@ func (s *BookmarkService) ListAllTags(ctx context.Context) ([]string, error) {
 	}
 	return tags, nil
 }
+
+func (s *BookmarkService) MergeTags(ctx context.Context, from []string, into string) (map[string]int, error) {
+	if strings.TrimSpace(into) == "" {
+		return nil, fmt.Errorf("target tag cannot be empty")
+	}
+	if len(from) == 0 {
+		return nil, fmt.Errorf("at least one source tag is required")
+	}
+	target := models.NormalizeTag(into)
+	for _, tag := range from {
+		if models.NormalizeTag(tag) == target {
+			return nil, fmt.Errorf("source tag '%s' is the same as the target tag", strings.TrimSpace(tag))
+		}
+	}
+
+	counts, err := s.repo.MergeTags(ctx, from, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge tags: %w", err)
+	}
+	return counts, nil
+}
+
+// RenameTag replaces oldTag with newTag on every bookmark carrying it, in one
+// transaction, and returns how many bookmarks changed. Bookmarks that already carry
+// newTag keep a single copy of it.
+func (s *BookmarkService) RenameTag(ctx context.Context, oldTag, newTag string) (int, error) {
+	if strings.TrimSpace(oldTag) == "" {
+		return 0, fmt.Errorf("tag to rename cannot be empty")
+	}
+	counts, err := s.MergeTags(ctx, []string{oldTag}, newTag)
+	if err != nil {
+		return 0, err
+	}
+	return counts[strings.ToLower(strings.TrimSpace(oldTag))], nil
+}
+
+// PruneTags removes tags no bookmark uses any more and returns them. With dryRun
+// nothing is removed.
+func (s *BookmarkService) PruneTags(ctx context.Context, dryRun bool) ([]string, error) {
+	tags, err := s.repo.PruneTags(ctx, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune tags: %w", err)
+	}
+	return tags, nil
+}
+
+// NormalizeTags rewrites every bookmark's tags in the canonical form: lowercased,
+// trimmed, without empty or repeated tags. It returns how many bookmarks changed, or
+// would change with dryRun.
+func (s *BookmarkService) NormalizeTags(ctx context.Context, dryRun bool) (int, error) {
+	changed, err := s.repo.NormalizeTags(ctx, dryRun)
+	if err != nil {
+		return 0, fmt.Errorf("failed to normalize tags: %w", err)
+	}
+	return changed, nil
+}
@@ -0,0 +1,33 @@
+package bookmarks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// ListDeletedBookmarks returns a page of the bookmarks in the trash, most recently
+// deleted first.
+func (s *BookmarkService) ListDeletedBookmarks(ctx context.Context, limit, offset int) ([]*models.Bookmark, error) {
+	bookmarks, err := s.repo.ListDeleted(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted bookmarks: %w", err)
+	}
+	return bookmarks, nil
+}
+
+// RestoreBookmark takes a bookmark out of the trash.
+func (s *BookmarkService) RestoreBookmark(ctx context.Context, id int64) error {
+	return s.repo.Restore(ctx, id)
+}
+
+// EmptyTrash permanently removes the bookmarks in the trash and returns how many
+// there were.
+func (s *BookmarkService) EmptyTrash(ctx context.Context) (int, error) {
+	purged, err := s.repo.PurgeDeleted(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+	return purged, nil
+}
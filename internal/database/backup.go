@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BackupTo writes a consistent copy of the bookmark database to path with VACUUM INTO.
+// The copy is taken inside a read transaction, so it sees a single snapshot even in
+// WAL mode and while other connections keep writing. It refuses to overwrite path.
+func (d *Database) BackupTo(ctx context.Context, path string) error {
+	if path == "" {
+		return fmt.Errorf("backup path is required")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup file %s already exists", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check backup file: %w", err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// RestoreFrom replaces every bookmark, including the trash, with the bookmarks in the
+// backup at path. The backup is attached to one connection and copied in a single
+// transaction, so a failure leaves the database as it was. Columns the backup predates
+// keep their defaults, relational tags are rebuilt from the tags column, and the cache
+// is cleared and its URL set refilled so it matches the restored bookmarks.
+func (d *Database) RestoreFrom(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS backup`, path); err != nil {
+		return fmt.Errorf("failed to attach backup: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `DETACH DATABASE backup`)
+
+	var tables int
+	err = conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM backup.sqlite_master WHERE type = 'table' AND name = 'bookmarks'`).Scan(&tables)
+	if err != nil {
+		return fmt.Errorf("failed to read backup schema: %w", err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("%s is not a goku database: it has no bookmarks table", path)
+	}
+
+	columns, err := sharedColumns(ctx, conn, "bookmarks")
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"bookmark_tags", "tags", "bookmarks"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM main."+table); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+	// Inserting the backup's IDs moves the autoincrement counter up to its highest one
+	if _, err := tx.ExecContext(ctx, "DELETE FROM main.sqlite_sequence WHERE name = 'bookmarks'"); err != nil {
+		return fmt.Errorf("failed to reset autoincrement: %w", err)
+	}
+
+	columnList := strings.Join(columns, ", ")
+	query := `INSERT INTO main.bookmarks (` + columnList + `) SELECT ` + columnList + ` FROM backup.bookmarks ORDER BY id`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to copy bookmarks from backup: %w", err)
+	}
+	if _, err := relinkAllTags(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return d.rebuildCache(ctx)
+}
+
+// sharedColumns returns the columns of table that exist both in the main database and
+// in the attached backup, in the main database's order. The backup must have an id
+// and a url column.
+func sharedColumns(ctx context.Context, conn *sql.Conn, table string) ([]string, error) {
+	mainColumns, err := tableColumns(ctx, conn, "main", table)
+	if err != nil {
+		return nil, err
+	}
+	backupColumns, err := tableColumns(ctx, conn, "backup", table)
+	if err != nil {
+		return nil, err
+	}
+
+	inBackup := make(map[string]bool, len(backupColumns))
+	for _, column := range backupColumns {
+		inBackup[column] = true
+	}
+	if !inBackup["id"] || !inBackup["url"] {
+		return nil, fmt.Errorf("backup %s table has no id or url column", table)
+	}
+
+	var shared []string
+	for _, column := range mainColumns {
+		if inBackup[column] {
+			shared = append(shared, column)
+		}
+	}
+	return shared, nil
+}
+
+// tableColumns returns the column names of table in schema, e.g. "main" or an
+// attached database.
+func tableColumns(ctx context.Context, db queryer, schema, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.table_info(%s)", schema, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s.%s schema: %w", schema, table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan %s.%s schema: %w", schema, table, err)
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s.%s schema: %w", schema, table, err)
+	}
+	return columns, nil
+}
+
+// rebuildCache clears the cache and refills its URL set from the live bookmarks, so
+// duplicate checks match the bookmarks table after it was replaced wholesale.
+func (d *Database) rebuildCache(ctx context.Context) error {
+	if err := d.cache.Clear(ctx); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+
+	rows, err := d.db.QueryContext(ctx, `SELECT url FROM bookmarks WHERE `+liveExpr)
+	if err != nil {
+		return fmt.Errorf("failed to query bookmark URLs: %w", err)
+	}
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan bookmark URL: %w", err)
+		}
+		urls = append(urls, url)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating bookmark URLs: %w", err)
+	}
+
+	for _, url := range urls {
+		if err := d.cache.AddURL(ctx, url); err != nil {
+			return fmt.Errorf("failed to rebuild URL set: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupTo(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	addTestBookmark(t, db, "https://a.example", "go")
+	second := addTestBookmark(t, db, "https://b.example", "web")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.db")
+	if err := db.BackupTo(ctx, path); err != nil {
+		t.Fatalf("BackupTo: %v", err)
+	}
+	if err := db.BackupTo(ctx, path); err == nil {
+		t.Error("BackupTo over an existing file succeeded, want an error")
+	}
+
+	backup, err := NewDatabase(path, filepath.Join(dir, "backup_cache.db"), "test", DefaultConnectionOptions)
+	if err != nil {
+		t.Fatalf("NewDatabase(backup): %v", err)
+	}
+	defer backup.Close()
+
+	count, err := backup.Count(ctx)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("backup has %d bookmarks, want 2", count)
+	}
+	bookmark, err := backup.GetByID(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("GetByID(backup): %v", err)
+	}
+	if bookmark.URL != second.URL || len(bookmark.Tags) != 1 || bookmark.Tags[0] != "web" {
+		t.Errorf("backup has %s %v, want %s [web]", bookmark.URL, bookmark.Tags, second.URL)
+	}
+}
+
+func TestRestoreFrom(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	kept := addTestBookmark(t, db, "https://a.example", "go")
+	trashed := addTestBookmark(t, db, "https://b.example", "web")
+	if err := db.Delete(ctx, trashed.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := db.BackupTo(ctx, path); err != nil {
+		t.Fatalf("BackupTo: %v", err)
+	}
+
+	// Changes made after the backup are undone by restoring it
+	if err := db.Delete(ctx, kept.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := db.PurgeDeleted(ctx); err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+	addTestBookmark(t, db, "https://c.example", "later")
+
+	if err := db.RestoreFrom(ctx, path); err != nil {
+		t.Fatalf("RestoreFrom: %v", err)
+	}
+
+	bookmark, err := db.GetByURL(ctx, kept.URL)
+	if err != nil || bookmark == nil {
+		t.Fatalf("GetByURL(%s) = %v, %v; want the restored bookmark", kept.URL, bookmark, err)
+	}
+	if bookmark.ID != kept.ID || len(bookmark.Tags) != 1 || bookmark.Tags[0] != "go" {
+		t.Errorf("restored bookmark %d %v, want %d [go]", bookmark.ID, bookmark.Tags, kept.ID)
+	}
+	if bookmark, err := db.GetByURL(ctx, "https://c.example"); err != nil || bookmark != nil {
+		t.Errorf("GetByURL(c.example) = %v, %v; want nil after restore", bookmark, err)
+	}
+
+	if found, err := db.Search(ctx, "later", 10, 0); err != nil || len(found) != 0 {
+		t.Errorf("Search(later) = %v, %v; want no results after restore", found, err)
+	}
+
+	deleted, err := db.ListDeleted(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListDeleted: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != trashed.ID {
+		t.Errorf("trash after restore = %v, want bookmark %d", deleted, trashed.ID)
+	}
+
+	tags, err := db.ListAllTags(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTags: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "go" {
+		t.Errorf("tags after restore = %v, want [go]", tags)
+	}
+
+	// New bookmarks continue after the restored IDs
+	next := addTestBookmark(t, db, "https://d.example")
+	if next.ID <= trashed.ID {
+		t.Errorf("new bookmark ID = %d, want above %d", next.ID, trashed.ID)
+	}
+}
+
+func TestRestoreFromRejectsOtherDatabases(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	addTestBookmark(t, db, "https://a.example")
+
+	dir := t.TempDir()
+	other, err := NewCacheDB(filepath.Join(dir, "other.db"), DefaultConnectionOptions)
+	if err != nil {
+		t.Fatalf("NewCacheDB: %v", err)
+	}
+	other.Close()
+
+	if err := db.RestoreFrom(ctx, filepath.Join(dir, "other.db")); err == nil {
+		t.Error("RestoreFrom(cache database) succeeded, want an error")
+	}
+	if err := db.RestoreFrom(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("RestoreFrom(missing file) succeeded, want an error")
+	}
+	if count, err := db.Count(ctx); err != nil || count != 1 {
+		t.Errorf("Count = %d, %v; want 1 after failed restores", count, err)
+	}
+}
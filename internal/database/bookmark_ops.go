@@ -11,19 +11,25 @@ import (
 	"github.com/fallrising/goku-cli/pkg/models"
 )
 
+// ErrDuplicateURL is returned by Create when a bookmark with the same URL is stored.
+var ErrDuplicateURL = errors.New("bookmark with this URL already exists")
+
 func (d *Database) Create(ctx context.Context, bookmark *models.Bookmark) error {
 	exists, err := d.cache.HasURL(ctx, bookmark.URL)
 	if err != nil {
 		return fmt.Errorf("failed to check URL existence in cache: %w", err)
 	}
 	if exists {
-		return fmt.Errorf("bookmark with this URL already exists")
+		return ErrDuplicateURL
 	}
 
-	query := `INSERT INTO bookmarks (url, title, description, tags) VALUES (?, ?, ?, ?)`
+	// An imported bookmark keeps the creation time and visits it had in its source
+	query := `INSERT INTO bookmarks (url, title, description, tags, kind, canonical_url, lang, feed_url, favorite, created_at, visit_count, last_visited)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), ?, ?)`
 	tags := strings.Join(bookmark.Tags, ",")
 
-	result, err := d.db.ExecContext(ctx, query, bookmark.URL, bookmark.Title, bookmark.Description, tags)
+	result, err := d.db.ExecContext(ctx, query, bookmark.URL, bookmark.Title, bookmark.Description, tags, bookmark.Kind, bookmark.CanonicalURL, bookmark.Language, bookmark.FeedURL, bookmark.Favorite,
+		timestampArg(bookmark.CreatedAt), bookmark.VisitCount, lastVisitedArg(bookmark))
 	if err != nil {
 		return fmt.Errorf("failed to insert bookmark: %w", err)
 	}
@@ -35,49 +41,48 @@ func (d *Database) Create(ctx context.Context, bookmark *models.Bookmark) error
 
 	bookmark.ID = id
 
+	if err := syncBookmarkTags(ctx, d.db, id, bookmark.Tags); err != nil {
+		return err
+	}
+
 	err = d.cache.AddURL(ctx, bookmark.URL)
 	if err != nil {
 		return fmt.Errorf("failed to add URL to cache set: %w", err)
 	}
 
-	err = d.cache.Set(ctx, fmt.Sprintf("bookmark:%d", id), bookmark, 1*time.Hour)
+	err = d.cache.Set(ctx, d.cacheKey(id), bookmark, 1*time.Hour)
 	if err != nil {
 		return fmt.Errorf("failed to cache bookmark: %w", err)
 	}
 
-	return nil
+	return d.invalidateTagList(ctx)
 }
 
 func (d *Database) GetByID(ctx context.Context, id int64) (*models.Bookmark, error) {
-	cachedBookmark, err := d.cache.Get(ctx, fmt.Sprintf("bookmark:%d", id))
+	cachedBookmark, err := d.cache.Get(ctx, d.cacheKey(id))
 	if err == nil && cachedBookmark != nil {
 		return cachedBookmark, nil
 	}
 
-	query := `SELECT id, url, title, description, tags, created_at, updated_at FROM bookmarks WHERE id = ?`
-
-	var bookmark models.Bookmark
-	var tags string
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks WHERE id = ? AND ` + liveExpr
 
-	err = d.db.QueryRowContext(ctx, query, id).Scan(
-		&bookmark.ID, &bookmark.URL, &bookmark.Title, &bookmark.Description,
-		&tags, &bookmark.CreatedAt, &bookmark.UpdatedAt,
-	)
+	bookmark, err := scanBookmark(d.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("bookmark not found")
 		}
 		return nil, fmt.Errorf("failed to get bookmark: %w", err)
 	}
+	if err := d.loadRelationalTags(ctx, d.db, []*models.Bookmark{bookmark}); err != nil {
+		return nil, err
+	}
 
-	bookmark.Tags = strings.Split(tags, ",")
-
-	err = d.cache.Set(ctx, fmt.Sprintf("bookmark:%d", id), &bookmark, 1*time.Hour)
+	err = d.cache.Set(ctx, d.cacheKey(id), bookmark, 1*time.Hour)
 	if err != nil {
 		return nil, fmt.Errorf("failed to cache bookmark: %w", err)
 	}
 
-	return &bookmark, nil
+	return bookmark, nil
 }
 
 func (d *Database) GetByURL(ctx context.Context, url string) (*models.Bookmark, error) {
@@ -89,63 +94,65 @@ func (d *Database) GetByURL(ctx context.Context, url string) (*models.Bookmark,
 		return nil, nil
 	}
 
-	query := `SELECT id, url, title, description, tags, created_at, updated_at FROM bookmarks WHERE url = ?`
-
-	var bookmark models.Bookmark
-	var tags string
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks WHERE url = ? AND ` + liveExpr
 
-	err = d.db.QueryRowContext(ctx, query, url).Scan(
-		&bookmark.ID, &bookmark.URL, &bookmark.Title, &bookmark.Description,
-		&tags, &bookmark.CreatedAt, &bookmark.UpdatedAt,
-	)
+	bookmark, err := scanBookmark(d.db.QueryRowContext(ctx, query, url))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get bookmark by URL: %w", err)
 	}
+	if err := d.loadRelationalTags(ctx, d.db, []*models.Bookmark{bookmark}); err != nil {
+		return nil, err
+	}
 
-	bookmark.Tags = strings.Split(tags, ",")
-
-	err = d.cache.Set(ctx, fmt.Sprintf("bookmark:%d", bookmark.ID), &bookmark, 1*time.Hour)
+	err = d.cache.Set(ctx, d.cacheKey(bookmark.ID), bookmark, 1*time.Hour)
 	if err != nil {
 		return nil, fmt.Errorf("failed to cache bookmark: %w", err)
 	}
 
-	return &bookmark, nil
+	return bookmark, nil
 }
 
 func (d *Database) Update(ctx context.Context, bookmark *models.Bookmark) error {
-	query := `UPDATE bookmarks SET url = ?, title = ?, description = ?, tags = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	query := `UPDATE bookmarks SET url = ?, title = ?, description = ?, tags = ?, kind = ?, canonical_url = ?, lang = ?, feed_url = ?, favorite = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
 	tags := strings.Join(bookmark.Tags, ",")
 
-	_, err := d.db.ExecContext(ctx, query, bookmark.URL, bookmark.Title, bookmark.Description, tags, bookmark.ID)
+	_, err := d.db.ExecContext(ctx, query, bookmark.URL, bookmark.Title, bookmark.Description, tags, bookmark.Kind, bookmark.CanonicalURL, bookmark.Language, bookmark.FeedURL, bookmark.Favorite, bookmark.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update bookmark: %w", err)
 	}
 
-	err = d.cache.Set(ctx, fmt.Sprintf("bookmark:%d", bookmark.ID), bookmark, 1*time.Hour)
+	if err := syncBookmarkTags(ctx, d.db, bookmark.ID, bookmark.Tags); err != nil {
+		return err
+	}
+
+	err = d.cache.Set(ctx, d.cacheKey(bookmark.ID), bookmark, 1*time.Hour)
 	if err != nil {
 		return fmt.Errorf("failed to update cached bookmark: %w", err)
 	}
 
-	return nil
+	return d.invalidateTagList(ctx)
 }
 
+// Delete moves a bookmark to the trash. It is left out of lists, searches and
+// counts, and its URL may be bookmarked again, until Restore brings it back or
+// PurgeDeleted removes it for good.
 func (d *Database) Delete(ctx context.Context, id int64) error {
 	bookmark, err := d.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get bookmark for deletion: %w", err)
 	}
 
-	query := `DELETE FROM bookmarks WHERE id = ?`
+	query := `UPDATE bookmarks SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND ` + liveExpr
 
 	_, err = d.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete bookmark: %w", err)
 	}
 
-	err = d.cache.Delete(ctx, fmt.Sprintf("bookmark:%d", id))
+	err = d.cache.Delete(ctx, d.cacheKey(id))
 	if err != nil {
 		return fmt.Errorf("failed to delete cached bookmark: %w", err)
 	}
@@ -155,12 +162,99 @@ func (d *Database) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to remove URL from cache set: %w", err)
 	}
 
+	err = d.cache.DeleteValidators(ctx, bookmark.URL)
+	if err != nil {
+		return fmt.Errorf("failed to delete fetch validators: %w", err)
+	}
+
+	return d.invalidateTagList(ctx)
+}
+
+// RecordVisit counts a visit to the bookmark with the given ID and sets its last
+// visit to now. The count is incremented in SQL, so concurrent visits are not lost.
+func (d *Database) RecordVisit(ctx context.Context, id int64) error {
+	query := `UPDATE bookmarks SET visit_count = visit_count + 1, last_visited = CURRENT_TIMESTAMP WHERE id = ? AND ` + liveExpr
+
+	result, err := d.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to record visit: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record visit: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("bookmark not found")
+	}
+
+	err = d.cache.Delete(ctx, d.cacheKey(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete cached bookmark: %w", err)
+	}
 	return nil
 }
 
 func (d *Database) List(ctx context.Context, limit, offset int) ([]*models.Bookmark, error) {
-	query := `SELECT id, url, title, description, tags, created_at, updated_at FROM bookmarks LIMIT ? OFFSET ?`
-	rows, err := d.db.QueryContext(ctx, query, limit, offset)
+	return d.ListFiltered(ctx, models.BookmarkFilter{}, limit, offset)
+}
+
+func (d *Database) ListFiltered(ctx context.Context, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, error) {
+	source, args := d.listSource(filter)
+	order, err := orderClause(filter.Sort, filter.SortDesc)
+	if err != nil {
+		return nil, err
+	}
+	return d.queryBookmarks(ctx, d.db, `SELECT `+bookmarkColumns+source+order+` LIMIT ? OFFSET ?`, append(args, limit, offset)...)
+}
+
+// ListWithCount returns the same page as ListFiltered along with the number of
+// bookmarks matching filter in all.
+func (d *Database) ListWithCount(ctx context.Context, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error) {
+	source, args := d.listSource(filter)
+	order, err := orderClause(filter.Sort, filter.SortDesc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return d.pageWithCount(ctx, source, args, order, nil, limit, offset)
+}
+
+// listSource returns the FROM and WHERE clauses selecting the bookmarks that match
+// filter, and their arguments.
+func (d *Database) listSource(filter models.BookmarkFilter) (string, []interface{}) {
+	source := ` FROM bookmarks`
+	where, args := buildFilterClause(filter, d.relationalTags)
+	if where != "" {
+		source += ` WHERE ` + where
+	}
+	return source, args
+}
+
+// pageWithCount counts the bookmarks selected by source and reads the page of them
+// given by limit and offset, sorted by order. Both queries run in one transaction,
+// so the total always describes the same rows as the page.
+func (d *Database) pageWithCount(ctx context.Context, source string, args []interface{}, order string, orderArgs []interface{}, limit, offset int) ([]*models.Bookmark, int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*)`+source, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	pageArgs := append(append(append([]interface{}{}, args...), orderArgs...), limit, offset)
+	bookmarks, err := d.queryBookmarks(ctx, tx, `SELECT `+bookmarkColumns+source+order+` LIMIT ? OFFSET ?`, pageArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bookmarks, total, nil
+}
+
+// queryBookmarks runs a query selecting bookmarkColumns and scans every row.
+func (d *Database) queryBookmarks(ctx context.Context, db queryer, query string, args ...interface{}) ([]*models.Bookmark, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query bookmarks: %w", err)
 	}
@@ -168,29 +262,37 @@ func (d *Database) List(ctx context.Context, limit, offset int) ([]*models.Bookm
 
 	var bookmarks []*models.Bookmark
 	for rows.Next() {
-		var bookmark models.Bookmark
-		var tags string
-		err := rows.Scan(
-			&bookmark.ID, &bookmark.URL, &bookmark.Title, &bookmark.Description,
-			&tags, &bookmark.CreatedAt, &bookmark.UpdatedAt,
-		)
+		bookmark, err := scanBookmark(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan bookmark row: %w", err)
 		}
-		bookmark.Tags = strings.Split(tags, ",")
-		bookmarks = append(bookmarks, &bookmark)
+		bookmarks = append(bookmarks, bookmark)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating bookmark rows: %w", err)
 	}
 
+	if err := d.loadRelationalTags(ctx, db, bookmarks); err != nil {
+		return nil, err
+	}
+	return bookmarks, nil
+}
+
+// ListRandom returns up to limit bookmarks chosen at random.
+func (d *Database) ListRandom(ctx context.Context, limit int) ([]*models.Bookmark, error) {
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks WHERE ` + liveExpr + ` ORDER BY RANDOM() LIMIT ?`
+
+	bookmarks, err := d.queryBookmarks(ctx, d.db, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query random bookmarks: %w", err)
+	}
 	return bookmarks, nil
 }
 
 func (d *Database) Count(ctx context.Context) (int, error) {
 	var count int
-	err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bookmarks").Scan(&count)
+	err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bookmarks WHERE "+liveExpr).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count bookmarks: %w", err)
 	}
@@ -207,12 +309,19 @@ func (d *Database) Purge(ctx context.Context) error {
 	// Defer a rollback in case anything fails
 	defer tx.Rollback()
 
-	// Delete all bookmarks
+	// Delete all bookmarks, including those in the trash
 	_, err = tx.ExecContext(ctx, "DELETE FROM bookmarks")
 	if err != nil {
 		return fmt.Errorf("failed to delete all bookmarks: %w", err)
 	}
 
+	// Delete all tags
+	for _, table := range []string{"bookmark_tags", "tags"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+
 	// Reset the autoincrement counter
 	_, err = tx.ExecContext(ctx, "DELETE FROM sqlite_sequence WHERE name='bookmarks'")
 	if err != nil {
@@ -232,3 +341,80 @@ func (d *Database) Purge(ctx context.Context) error {
 
 	return nil
 }
+
+// CreateBatch inserts bookmarks in a single transaction. Bookmarks whose URL already
+// exists outside the trash are skipped rather than failing the batch; only inserted
+// bookmarks get an ID and are added to the cache. It returns the number of bookmarks
+// actually inserted.
+//
+// Each bookmark is inserted by its own exec of one prepared statement rather than in
+// multi-row VALUES: the single transaction is what makes a large import fast, and a
+// per-row exec gives each bookmark its ID and tag links and lets duplicates be told
+// apart by the affected row count.
+func (d *Database) CreateBatch(ctx context.Context, bookmarks []*models.Bookmark) (int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The bookmarks table has no unique constraint on url, so duplicates are filtered
+	// in the statement itself and detected through the affected row count.
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO bookmarks (url, title, description, tags, kind, canonical_url, lang, feed_url, favorite, created_at, visit_count, last_visited)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), ?, ? WHERE NOT EXISTS (SELECT 1 FROM bookmarks WHERE url = ? AND `+liveExpr+`)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var inserted []*models.Bookmark
+	for _, bookmark := range bookmarks {
+		tags := strings.Join(bookmark.Tags, ",")
+		result, err := stmt.ExecContext(ctx, bookmark.URL, bookmark.Title, bookmark.Description, tags, bookmark.Kind, bookmark.CanonicalURL, bookmark.Language, bookmark.FeedURL, bookmark.Favorite,
+			timestampArg(bookmark.CreatedAt), bookmark.VisitCount, lastVisitedArg(bookmark), bookmark.URL)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert bookmark %s: %w", bookmark.URL, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if affected == 0 {
+			continue // URL already present
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get last insert ID: %w", err)
+		}
+		bookmark.ID = id
+		if _, err := linkBookmarkTags(ctx, tx, id, bookmark.Tags); err != nil {
+			return 0, err
+		}
+		inserted = append(inserted, bookmark)
+	}
+
+	if err := tx.Commit(); err != nil {
+		for _, bookmark := range inserted {
+			bookmark.ID = 0
+		}
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, bookmark := range inserted {
+		if err := d.cache.AddURL(ctx, bookmark.URL); err != nil {
+			return len(inserted), fmt.Errorf("failed to add URL to cache set: %w", err)
+		}
+		if err := d.cache.Set(ctx, d.cacheKey(bookmark.ID), bookmark, 1*time.Hour); err != nil {
+			return len(inserted), fmt.Errorf("failed to cache bookmark: %w", err)
+		}
+	}
+	if len(inserted) > 0 {
+		if err := d.invalidateTagList(ctx); err != nil {
+			return len(inserted), err
+		}
+	}
+
+	return len(inserted), nil
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestRecordVisit(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	rare := addTestBookmark(t, db, "https://rare.example")
+	popular := addTestBookmark(t, db, "https://popular.example")
+	addTestBookmark(t, db, "https://never.example")
+
+	if err := db.RecordVisit(ctx, rare.ID); err != nil {
+		t.Fatalf("RecordVisit: %v", err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- db.RecordVisit(ctx, popular.ID)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent RecordVisit: %v", err)
+		}
+	}
+	if err := db.RecordVisit(ctx, 9999); err == nil {
+		t.Errorf("RecordVisit on a missing bookmark succeeded")
+	}
+
+	got, err := db.GetByID(ctx, popular.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.VisitCount != 10 || got.LastVisited == nil {
+		t.Errorf("popular bookmark has %d visits, last %v; want 10 and a time", got.VisitCount, got.LastVisited)
+	}
+
+	bookmarks, err := db.ListFiltered(ctx, models.BookmarkFilter{Sort: models.SortByVisits, SortDesc: true}, 10, 0)
+	if err != nil {
+		t.Fatalf("ListFiltered: %v", err)
+	}
+	var urls []string
+	for _, bookmark := range bookmarks {
+		urls = append(urls, bookmark.URL)
+	}
+	if want := []string{"https://popular.example", "https://rare.example", "https://never.example"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("sorted by visits:desc = %v, want %v", urls, want)
+	}
+}
@@ -19,8 +19,8 @@ type CacheDB struct {
 	mu sync.RWMutex
 }
 
-func NewCacheDB(dbPath string) (*CacheDB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+func NewCacheDB(dbPath string, options ConnectionOptions) (*CacheDB, error) {
+	db, err := sql.Open("sqlite3", options.dsn(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open cache database: %w", err)
 	}
@@ -37,6 +37,13 @@ func NewCacheDB(dbPath string) (*CacheDB, error) {
 	return cacheDB, nil
 }
 
+func (c *CacheDB) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.db.Close()
+}
+
 func (c *CacheDB) initSchema() error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS bookmark_cache (
@@ -47,6 +54,11 @@ func (c *CacheDB) initSchema() error {
 		`CREATE TABLE IF NOT EXISTS url_set (
 			url TEXT PRIMARY KEY
 		)`,
+		`CREATE TABLE IF NOT EXISTS fetch_validators (
+			url TEXT PRIMARY KEY,
+			etag TEXT NOT NULL DEFAULT '',
+			last_modified TEXT NOT NULL DEFAULT ''
+		)`,
 	}
 
 	for _, query := range queries {
@@ -108,6 +120,55 @@ func (c *CacheDB) Get(ctx context.Context, key string) (*models.Bookmark, error)
 	return &bookmark, nil
 }
 
+// SetTags caches a list of tags under key. It shares the bookmark_cache table with
+// cached bookmarks.
+func (c *CacheDB) SetTags(ctx context.Context, key string, tags []string, expiry time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := `INSERT OR REPLACE INTO bookmark_cache (key, data, expiry) VALUES (?, ?, ?)`
+	_, err = c.db.ExecContext(ctx, query, key, data, time.Now().Add(expiry))
+	if err != nil {
+		return fmt.Errorf("failed to set cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetTags returns the tags cached under key. ok is false on a cache miss or when the
+// entry has expired.
+func (c *CacheDB) GetTags(ctx context.Context, key string) (tags []string, ok bool, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	query := `SELECT data, expiry FROM bookmark_cache WHERE key = ?`
+	var data []byte
+	var expiry time.Time
+
+	err = c.db.QueryRowContext(ctx, query, key).Scan(&data, &expiry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get cache entry: %w", err)
+	}
+
+	if time.Now().After(expiry) {
+		return nil, false, nil
+	}
+
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	return tags, true, nil
+}
+
 func (c *CacheDB) Delete(ctx context.Context, key string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -164,6 +225,56 @@ func (c *CacheDB) RemoveURL(ctx context.Context, url string) error {
 	return nil
 }
 
+// SetValidators records the ETag and Last-Modified values last served for url.
+// Passing both empty forgets them.
+func (c *CacheDB) SetValidators(ctx context.Context, url, etag, lastModified string) error {
+	if etag == "" && lastModified == "" {
+		return c.DeleteValidators(ctx, url)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := `INSERT OR REPLACE INTO fetch_validators (url, etag, last_modified) VALUES (?, ?, ?)`
+	_, err := c.db.ExecContext(ctx, query, url, etag, lastModified)
+	if err != nil {
+		return fmt.Errorf("failed to set fetch validators: %w", err)
+	}
+
+	return nil
+}
+
+// GetValidators returns the ETag and Last-Modified values recorded for url, both
+// empty if there are none.
+func (c *CacheDB) GetValidators(ctx context.Context, url string) (etag, lastModified string, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	query := `SELECT etag, last_modified FROM fetch_validators WHERE url = ?`
+	err = c.db.QueryRowContext(ctx, query, url).Scan(&etag, &lastModified)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("failed to get fetch validators: %w", err)
+	}
+
+	return etag, lastModified, nil
+}
+
+func (c *CacheDB) DeleteValidators(ctx context.Context, url string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	query := `DELETE FROM fetch_validators WHERE url = ?`
+	_, err := c.db.ExecContext(ctx, query, url)
+	if err != nil {
+		return fmt.Errorf("failed to delete fetch validators: %w", err)
+	}
+
+	return nil
+}
+
 func (c *CacheDB) Clear(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -178,5 +289,10 @@ func (c *CacheDB) Clear(ctx context.Context) error {
 		return fmt.Errorf("failed to clear URL set: %w", err)
 	}
 
+	_, err = c.db.ExecContext(ctx, "DELETE FROM fetch_validators")
+	if err != nil {
+		return fmt.Errorf("failed to clear fetch validators: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchValidators(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	bookmark := addTestBookmark(t, db, "https://example.com/a")
+
+	assertValidators := func(wantETag, wantLastModified string) {
+		t.Helper()
+		etag, lastModified, err := db.GetFetchValidators(ctx, bookmark.URL)
+		if err != nil {
+			t.Fatalf("GetFetchValidators: %v", err)
+		}
+		if etag != wantETag || lastModified != wantLastModified {
+			t.Errorf("validators = (%q, %q), want (%q, %q)", etag, lastModified, wantETag, wantLastModified)
+		}
+	}
+
+	assertValidators("", "")
+
+	const modified = "Fri, 01 Mar 2024 12:00:00 GMT"
+	if err := db.SetFetchValidators(ctx, bookmark.URL, `"v1"`, modified); err != nil {
+		t.Fatalf("SetFetchValidators: %v", err)
+	}
+	assertValidators(`"v1"`, modified)
+
+	// A fetch that returned no validators forgets the old ones
+	if err := db.SetFetchValidators(ctx, bookmark.URL, "", ""); err != nil {
+		t.Fatalf("SetFetchValidators: %v", err)
+	}
+	assertValidators("", "")
+
+	if err := db.SetFetchValidators(ctx, bookmark.URL, `"v2"`, ""); err != nil {
+		t.Fatalf("SetFetchValidators: %v", err)
+	}
+	if err := db.Delete(ctx, bookmark.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertValidators("", "")
+}
@@ -3,19 +3,125 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/fallrising/goku-cli/pkg/models"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// bookmarkColumns lists the bookmark columns in the order scanBookmark expects them.
+const bookmarkColumns = `id, url, title, description, tags, created_at, updated_at, kind, canonical_url, lang, feed_url, deleted_at, favorite, visit_count, last_visited`
+
+// sqliteTimeFormat matches what CURRENT_TIMESTAMP writes, so timestamps given by the
+// caller sort and compare like the ones SQLite fills in.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// timestampArg formats t in UTC for a DATETIME column, or returns nil for the zero
+// time so COALESCE(?, CURRENT_TIMESTAMP) falls back to the current time.
+func timestampArg(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// lastVisitedArg returns the last_visited value for bookmark, NULL if it was never
+// visited.
+func lastVisitedArg(bookmark *models.Bookmark) interface{} {
+	if bookmark.LastVisited == nil {
+		return nil
+	}
+	return timestampArg(*bookmark.LastVisited)
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// scanBookmark reads a row selected with bookmarkColumns.
+func scanBookmark(row rowScanner) (*models.Bookmark, error) {
+	var bookmark models.Bookmark
+	var tags string
+	var deletedAt, lastVisited sql.NullTime
+	err := row.Scan(
+		&bookmark.ID, &bookmark.URL, &bookmark.Title, &bookmark.Description,
+		&tags, &bookmark.CreatedAt, &bookmark.UpdatedAt, &bookmark.Kind, &bookmark.CanonicalURL, &bookmark.Language, &bookmark.FeedURL,
+		&deletedAt, &bookmark.Favorite, &bookmark.VisitCount, &lastVisited,
+	)
+	if err != nil {
+		return nil, err
+	}
+	bookmark.Tags = strings.Split(tags, ",")
+	if deletedAt.Valid {
+		bookmark.DeletedAt = &deletedAt.Time
+	}
+	if lastVisited.Valid {
+		bookmark.LastVisited = &lastVisited.Time
+	}
+	return &bookmark, nil
+}
+
 type Database struct {
-	db    *sql.DB
-	cache *CacheDB
+	db             *sql.DB
+	cache          *CacheDB
+	user           string
+	relationalTags bool // read tags from the tags/bookmark_tags tables; see SetRelationalTags
+	fts            bool // search through the bookmarks_fts index; see initFTS
 }
 
-func NewDatabase(dbPath string, cacheDBPath string) (*Database, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+// ConnectionOptions tune how SQLite opens the bookmark and cache databases. Empty
+// fields leave SQLite's own defaults in place.
+type ConnectionOptions struct {
+	JournalMode string        // e.g. "WAL", so readers don't block on a writer
+	BusyTimeout time.Duration // how long to wait for a lock held by another process
+	Synchronous string        // e.g. "NORMAL", which is safe in WAL mode and syncs less
+}
+
+// DefaultConnectionOptions let a long import and searches from another terminal use
+// the database at the same time instead of failing with "database is locked".
+var DefaultConnectionOptions = ConnectionOptions{
+	JournalMode: "WAL",
+	BusyTimeout: 5 * time.Second,
+	Synchronous: "NORMAL",
+}
+
+// dsn returns the go-sqlite3 data source name opening path with o.
+func (o ConnectionOptions) dsn(path string) string {
+	params := url.Values{}
+	if o.JournalMode != "" {
+		params.Set("_journal_mode", o.JournalMode)
+	}
+	if o.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10))
+	}
+	if o.Synchronous != "" {
+		params.Set("_synchronous", o.Synchronous)
+	}
+	if len(params) == 0 {
+		return path
+	}
+	if strings.Contains(path, "?") {
+		return path + "&" + params.Encode()
+	}
+	return path + "?" + params.Encode()
+}
+
+// NewDatabase opens the bookmark and cache databases for user with options. The user
+// namespaces cache keys so several profiles can share one cache database.
+func NewDatabase(dbPath string, cacheDBPath string, user string, options ConnectionOptions) (*Database, error) {
+	db, err := sql.Open("sqlite3", options.dsn(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -24,12 +130,67 @@ func NewDatabase(dbPath string, cacheDBPath string) (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	cacheDB, err := NewCacheDB(cacheDBPath)
+	cacheDB, err := NewCacheDB(cacheDBPath, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cache database: %w", err)
 	}
 
-	return &Database{db: db, cache: cacheDB}, nil
+	return &Database{db: db, cache: cacheDB, user: user}, nil
+}
+
+// cacheKey returns the cache key for a bookmark, e.g. "alice:bookmark:42". Without a
+// user it falls back to the unprefixed "bookmark:42".
+func (d *Database) cacheKey(id int64) string {
+	if d.user == "" {
+		return fmt.Sprintf("bookmark:%d", id)
+	}
+	return fmt.Sprintf("%s:bookmark:%d", d.user, id)
+}
+
+// tagListTTL is how long ListAllTags serves the tag list from the cache. Writes
+// that can change tags drop the cached list sooner.
+const tagListTTL = 5 * time.Minute
+
+// tagListCacheKey returns the cache key for the user's tag list, e.g. "alice:tags".
+func (d *Database) tagListCacheKey() string {
+	if d.user == "" {
+		return "tags"
+	}
+	return d.user + ":tags"
+}
+
+// invalidateTagList drops the cached tag list after a write that may change tags.
+func (d *Database) invalidateTagList(ctx context.Context) error {
+	if err := d.cache.Delete(ctx, d.tagListCacheKey()); err != nil {
+		return fmt.Errorf("failed to invalidate cached tag list: %w", err)
+	}
+	return nil
+}
+
+// GetFetchValidators returns the ETag and Last-Modified values recorded when url was
+// last fetched, both empty if there are none.
+func (d *Database) GetFetchValidators(ctx context.Context, url string) (etag, lastModified string, err error) {
+	return d.cache.GetValidators(ctx, url)
+}
+
+// SetFetchValidators records the ETag and Last-Modified values url was served with,
+// for conditional fetches. Passing both empty forgets them.
+func (d *Database) SetFetchValidators(ctx context.Context, url, etag, lastModified string) error {
+	return d.cache.SetValidators(ctx, url, etag, lastModified)
+}
+
+// Close closes the bookmark database and its cache, letting SQLite checkpoint and
+// release its files. Both are closed even if the first fails.
+func (d *Database) Close() error {
+	dbErr := d.db.Close()
+	cacheErr := d.cache.Close()
+	if dbErr != nil {
+		return fmt.Errorf("failed to close database: %w", dbErr)
+	}
+	if cacheErr != nil {
+		return fmt.Errorf("failed to close cache database: %w", cacheErr)
+	}
+	return nil
 }
 
 func (d *Database) Init() error {
@@ -48,5 +209,68 @@ func (d *Database) Init() error {
 		return fmt.Errorf("failed to create bookmarks table: %w", err)
 	}
 
+	// Columns added after the original schema; existing databases gain them here
+	if err := d.addColumnIfMissing("bookmarks", "kind", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("bookmarks", "canonical_url", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("bookmarks", "lang", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("bookmarks", "feed_url", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// NULL unless the bookmark is in the trash; see trash_ops.go
+	if err := d.addColumnIfMissing("bookmarks", "deleted_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("bookmarks", "favorite", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("bookmarks", "visit_count", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := d.addColumnIfMissing("bookmarks", "last_visited", "DATETIME"); err != nil {
+		return err
+	}
+
+	if err := d.initTagTables(); err != nil {
+		return err
+	}
+	if err := d.initFTS(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addColumnIfMissing adds column to table unless a previous run already did.
+func (d *Database) addColumnIfMissing(table, column, definition string) error {
+	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to read %s schema: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to scan %s schema: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s schema: %w", table, err)
+	}
+
+	if _, err := d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
 	return nil
 }
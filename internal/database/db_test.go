@@ -0,0 +1,48 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnectionOptions(t *testing.T) {
+	db := newTestDatabase(t)
+
+	for _, conn := range []struct {
+		name   string
+		pragma func(query string, dest interface{}) error
+	}{
+		{"bookmarks", func(query string, dest interface{}) error { return db.db.QueryRow(query).Scan(dest) }},
+		{"cache", func(query string, dest interface{}) error { return db.cache.db.QueryRow(query).Scan(dest) }},
+	} {
+		var journalMode string
+		var busyTimeout, synchronous int
+		if err := conn.pragma(`PRAGMA journal_mode`, &journalMode); err != nil || journalMode != "wal" {
+			t.Errorf("%s: journal_mode = %q, %v; want wal", conn.name, journalMode, err)
+		}
+		if err := conn.pragma(`PRAGMA busy_timeout`, &busyTimeout); err != nil || busyTimeout != 5000 {
+			t.Errorf("%s: busy_timeout = %d, %v; want 5000", conn.name, busyTimeout, err)
+		}
+		// 1 is NORMAL
+		if err := conn.pragma(`PRAGMA synchronous`, &synchronous); err != nil || synchronous != 1 {
+			t.Errorf("%s: synchronous = %d, %v; want 1", conn.name, synchronous, err)
+		}
+	}
+}
+
+func TestConnectionOptionsDSN(t *testing.T) {
+	tests := []struct {
+		path    string
+		options ConnectionOptions
+		want    string
+	}{
+		{"goku.db", ConnectionOptions{}, "goku.db"},
+		{"goku.db", DefaultConnectionOptions, "goku.db?_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL"},
+		{"file:goku.db?mode=ro", ConnectionOptions{BusyTimeout: 250 * time.Millisecond}, "file:goku.db?mode=ro&_busy_timeout=250"},
+	}
+	for _, tt := range tests {
+		if got := tt.options.dsn(tt.path); got != tt.want {
+			t.Errorf("dsn(%q) with %+v = %q, want %q", tt.path, tt.options, got, tt.want)
+		}
+	}
+}
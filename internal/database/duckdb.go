@@ -28,8 +28,12 @@ func NewDuckDBStats(dbPath string) (*DuckDBStats, error) {
 	return &DuckDBStats{db: db}, nil
 }
 
-func (d *DuckDBStats) Init() error {
-	_, err := d.db.Exec(`
+func (d *DuckDBStats) Close() error {
+	return d.db.Close()
+}
+
+// duckDBBookmarksTable creates the DuckDB copy of the bookmarks table.
+const duckDBBookmarksTable = `
 		CREATE TABLE IF NOT EXISTS bookmarks (
 			id INTEGER PRIMARY KEY,
 			url TEXT NOT NULL,
@@ -39,7 +43,10 @@ func (d *DuckDBStats) Init() error {
 			created_at TIMESTAMP,
 			updated_at TIMESTAMP
 		)
-	`)
+	`
+
+func (d *DuckDBStats) Init() error {
+	_, err := d.db.Exec(duckDBBookmarksTable)
 	if err != nil {
 		return fmt.Errorf("failed to create bookmarks table in DuckDB: %w", err)
 	}
@@ -54,11 +61,16 @@ func (d *DuckDBStats) SyncFromSQLite(sqliteDB *Database) error {
 	}
 	defer tx.Rollback()
 
-	// Clear existing data
-	_, err = tx.Exec("DELETE FROM bookmarks")
+	// Clear existing data. The table is recreated rather than emptied because DuckDB
+	// rejects reinserting a deleted primary key in the same transaction.
+	_, err = tx.Exec("DROP TABLE IF EXISTS bookmarks")
 	if err != nil {
 		return fmt.Errorf("failed to clear existing data: %w", err)
 	}
+	_, err = tx.Exec(duckDBBookmarksTable)
+	if err != nil {
+		return fmt.Errorf("failed to recreate bookmarks table in DuckDB: %w", err)
+	}
 
 	// Fetch all bookmarks from SQLite
 	bookmarks, err := sqliteDB.List(context.Background(), -1, 0) // Fetch all bookmarks
@@ -92,18 +104,20 @@ func (d *DuckDBStats) SyncFromSQLite(sqliteDB *Database) error {
 	return nil
 }
 
-func (d *DuckDBStats) GetStatistics(ctx context.Context) (*models.Statistics, error) {
+// GetStatistics builds the stats report, limiting the top hostnames to top entries
+// and the daily creation counts to the last days days.
+func (d *DuckDBStats) GetStatistics(ctx context.Context, days, top int) (*models.Statistics, error) {
 	stats := &models.Statistics{
 		HostnameCounts:      make(map[string]int),
 		TagCounts:           make(map[string]int),
 		AccessibilityCounts: make(map[string]int),
-		CreatedLastWeek:     make(map[string]int),
+		CreatedByDay:        make(map[string]int),
 	}
 
 	var err error
 
 	// Top Hostnames
-	stats.TopHostnames, err = d.getTopHostnames(ctx, 3)
+	stats.TopHostnames, err = d.getTopHostnames(ctx, top)
 	if err != nil {
 		return nil, err
 	}
@@ -138,8 +152,8 @@ func (d *DuckDBStats) GetStatistics(ctx context.Context) (*models.Statistics, er
 		return nil, err
 	}
 
-	// Created Last Week
-	stats.CreatedLastWeek, err = d.getCreatedLastWeek(ctx)
+	// Created in the last N days
+	stats.CreatedByDay, err = d.getCreatedLastNDays(ctx, days)
 	if err != nil {
 		return nil, err
 	}
@@ -218,13 +232,6 @@ func (d *DuckDBStats) getTagCounts(ctx context.Context) (map[string]int, error)
 	// Map to hold the counts
 	counts := make(map[string]int)
 
-	// Retrieve column names for debugging (optional)
-	columnNames, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get column names: %w", err)
-	}
-	fmt.Printf("Columns: %v\n", columnNames)
-
 	// Loop through the rows
 	for rows.Next() {
 		var tag string
@@ -235,9 +242,6 @@ func (d *DuckDBStats) getTagCounts(ctx context.Context) (map[string]int, error)
 			return nil, fmt.Errorf("failed to scan tag count: %w", err)
 		}
 
-		// Print the values for debugging
-		fmt.Printf("Tag: %s, Count: %d\n", tag, count)
-
 		// Trim the tag and store in the map
 		counts[strings.TrimSpace(tag)] = int(count)
 	}
@@ -331,17 +335,17 @@ func (d *DuckDBStats) getUniqueHostnames(ctx context.Context) ([]string, error)
 	return hostnames, nil
 }
 
-func (d *DuckDBStats) getCreatedLastWeek(ctx context.Context) (map[string]int, error) {
+func (d *DuckDBStats) getCreatedLastNDays(ctx context.Context, days int) (map[string]int, error) {
 	query := `
 		SELECT 
 			strftime(created_at, '%Y-%m-%d') as day, 
 			COUNT(*) as count 
 		FROM bookmarks 
-		WHERE created_at >= current_date - interval '7 days'
+		WHERE created_at >= current_date - CAST(? AS INTERVAL)
 		GROUP BY day 
 		ORDER BY day DESC
 	`
-	rows, err := d.db.QueryContext(ctx, query)
+	rows, err := d.db.QueryContext(ctx, query, fmt.Sprintf("%d days", days))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query created counts: %w", err)
 	}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// tagMatchExpr matches a whole tag inside the comma-separated tags column, so
+// filtering on "go" does not also match "golang".
+const tagMatchExpr = `instr(lower(',' || replace(coalesce(tags, ''), ', ', ',') || ','), ?) > 0`
+
+// liveExpr matches bookmarks that are not in the trash.
+const liveExpr = `deleted_at IS NULL`
+
+// buildFilterClause turns a filter into SQL predicates joined with AND, along with
+// their arguments. It returns an empty string when the filter matches everything.
+// relationalTags matches tags through bookmark_tags instead of the tags column.
+func buildFilterClause(filter models.BookmarkFilter, relationalTags bool) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	switch {
+	case filter.OnlyTrashed:
+		clauses = append(clauses, "deleted_at IS NOT NULL")
+	case !filter.IncludeTrashed:
+		clauses = append(clauses, liveExpr)
+	}
+
+	matchExpr, matchArg := tagMatchExpr, func(tag string) interface{} { return "," + strings.ToLower(tag) + "," }
+	if relationalTags {
+		matchExpr, matchArg = relationalTagMatchExpr, func(tag string) interface{} { return tag }
+	}
+
+	var tagClauses []string
+	for _, tag := range filter.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		tagClauses = append(tagClauses, matchExpr)
+		args = append(args, matchArg(tag))
+	}
+	if filter.AnyTags && len(tagClauses) > 1 {
+		clauses = append(clauses, "("+strings.Join(tagClauses, " OR ")+")")
+	} else {
+		clauses = append(clauses, tagClauses...)
+	}
+
+	for _, tag := range filter.ExcludeTags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		clauses = append(clauses, "NOT "+matchExpr)
+		args = append(args, matchArg(tag))
+	}
+
+	if host := strings.ToLower(strings.TrimSpace(filter.Host)); host != "" {
+		hosts := []string{host}
+		if filter.StripWWW {
+			bare := strings.TrimPrefix(host, "www.")
+			hosts = []string{bare, "www." + bare}
+		}
+
+		var hostClauses []string
+		for _, h := range hosts {
+			for _, pattern := range hostPatterns(h) {
+				hostClauses = append(hostClauses, `lower(url) LIKE ? ESCAPE '\'`)
+				args = append(args, pattern)
+			}
+		}
+		clauses = append(clauses, "("+strings.Join(hostClauses, " OR ")+")")
+	}
+
+	if kind := strings.ToLower(strings.TrimSpace(filter.Kind)); kind != "" {
+		clauses = append(clauses, "kind = ?")
+		args = append(args, kind)
+	}
+
+	if lang := strings.ToLower(strings.TrimSpace(filter.Language)); lang != "" {
+		// A bare language also matches its regional variants
+		clauses = append(clauses, `(lower(lang) = ? OR lower(lang) LIKE ? ESCAPE '\')`)
+		args = append(args, lang, likeEscaper.Replace(lang)+"-%")
+	}
+
+	if filter.Favorites {
+		clauses = append(clauses, "favorite = 1")
+	}
+
+	if !filter.UpdatedSince.IsZero() {
+		// updated_at is written by CURRENT_TIMESTAMP, i.e. UTC
+		clauses = append(clauses, "datetime(updated_at) >= datetime(?)")
+		args = append(args, filter.UpdatedSince.UTC().Format(sqliteTimeFormat))
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// sortColumns maps each SortField to the expression it orders by. Only these are
+// ever put into a query.
+var sortColumns = map[models.SortField]string{
+	models.SortByID:      "id",
+	models.SortByCreated: "created_at",
+	models.SortByUpdated: "updated_at",
+	models.SortByTitle:   "title COLLATE NOCASE",
+	models.SortByURL:     "url",
+	models.SortByVisits:  "visit_count",
+}
+
+// orderClause returns the ORDER BY clause for sort, defaulting to ID order. Ties are
+// broken by ascending ID.
+func orderClause(sort models.SortField, desc bool) (string, error) {
+	if sort == "" {
+		sort = models.SortByID
+	}
+	column, ok := sortColumns[sort]
+	if !ok {
+		return "", fmt.Errorf("unknown sort field %q", sort)
+	}
+	if desc {
+		column += " DESC"
+	}
+	if sort != models.SortByID {
+		column += ", id"
+	}
+	return " ORDER BY " + column, nil
+}
+
+// hostPatterns returns LIKE patterns matching URLs whose host is exactly host,
+// whatever follows it (nothing, a path, a port, a query, or a fragment).
+func hostPatterns(host string) []string {
+	escaped := likeEscaper.Replace(host)
+	return []string{
+		"%://" + escaped,
+		"%://" + escaped + "/%",
+		"%://" + escaped + ":%",
+		"%://" + escaped + "?%",
+		"%://" + escaped + "#%",
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+func TestListFiltered(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	addTestBookmark(t, db, "https://go.dev/doc", "go", "docs")
+	addTestBookmark(t, db, "https://www.golang.org", "golang")
+	// Tags written by older versions may be separated by ", "
+	addTestBookmark(t, db, "https://example.com:8080/x", "Web", " go")
+	addTestBookmark(t, db, "https://notexample.com", "web")
+
+	tests := []struct {
+		name   string
+		filter models.BookmarkFilter
+		want   []string
+	}{
+		{"no filter", models.BookmarkFilter{}, []string{"https://go.dev/doc", "https://www.golang.org", "https://example.com:8080/x", "https://notexample.com"}},
+		{"whole tag only", models.BookmarkFilter{Tags: []string{"go"}}, []string{"https://go.dev/doc", "https://example.com:8080/x"}},
+		{"tag is case-insensitive", models.BookmarkFilter{Tags: []string{"WEB"}}, []string{"https://example.com:8080/x", "https://notexample.com"}},
+		{"tags are ANDed", models.BookmarkFilter{Tags: []string{"go", "web"}}, []string{"https://example.com:8080/x"}},
+		{"any tag", models.BookmarkFilter{Tags: []string{"golang", "docs"}, AnyTags: true}, []string{"https://go.dev/doc", "https://www.golang.org"}},
+		{"any tag with exclusion", models.BookmarkFilter{Tags: []string{"go", "web"}, AnyTags: true, ExcludeTags: []string{"docs"}}, []string{"https://example.com:8080/x", "https://notexample.com"}},
+		{"excluded tag", models.BookmarkFilter{ExcludeTags: []string{"go"}}, []string{"https://www.golang.org", "https://notexample.com"}},
+		{"host matches exactly", models.BookmarkFilter{Host: "example.com"}, []string{"https://example.com:8080/x"}},
+		{"host keeps www by default", models.BookmarkFilter{Host: "golang.org"}, nil},
+		{"host ignores www when stripping", models.BookmarkFilter{Host: "golang.org", StripWWW: true}, []string{"https://www.golang.org"}},
+	}
+
+	for _, relational := range []bool{false, true} {
+		db.SetRelationalTags(relational)
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/relational=%t", tt.name, relational), func(t *testing.T) {
+				bookmarks, err := db.ListFiltered(ctx, tt.filter, 100, 0)
+				if err != nil {
+					t.Fatalf("ListFiltered: %v", err)
+				}
+				var got []string
+				for _, bookmark := range bookmarks {
+					got = append(got, bookmark.URL)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListFilteredUpdatedSince(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	old := addTestBookmark(t, db, "https://old.example")
+	addTestBookmark(t, db, "https://new.example")
+	if _, err := db.db.Exec(`UPDATE bookmarks SET updated_at = '2020-01-01 00:00:00' WHERE id = ?`, old.ID); err != nil {
+		t.Fatalf("backdating bookmark: %v", err)
+	}
+
+	filter := models.BookmarkFilter{UpdatedSince: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	bookmarks, err := db.ListFiltered(ctx, filter, 100, 0)
+	if err != nil {
+		t.Fatalf("ListFiltered: %v", err)
+	}
+	if len(bookmarks) != 1 || bookmarks[0].URL != "https://new.example" {
+		t.Errorf("got %v, want only https://new.example", bookmarks)
+	}
+}
+
+func TestListFilteredLanguage(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	for url, lang := range map[string]string{
+		"https://example.com/en":    "en",
+		"https://example.com/en-us": "en-US",
+		"https://example.com/eng":   "eng",
+		"https://example.com/de":    "de",
+		"https://example.com/none":  "",
+	} {
+		if err := db.Create(ctx, &models.Bookmark{URL: url, Language: lang}); err != nil {
+			t.Fatalf("Create(%s): %v", url, err)
+		}
+	}
+
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"en", []string{"https://example.com/en", "https://example.com/en-us"}},
+		{"EN-us", []string{"https://example.com/en-us"}},
+		{"de", []string{"https://example.com/de"}},
+		{"fr", nil},
+	}
+	for _, tt := range tests {
+		bookmarks, err := db.ListFiltered(ctx, models.BookmarkFilter{Language: tt.lang}, 100, 0)
+		if err != nil {
+			t.Fatalf("ListFiltered(%q): %v", tt.lang, err)
+		}
+		var got []string
+		for _, bookmark := range bookmarks {
+			got = append(got, bookmark.URL)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lang %q: got %v, want %v", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestListFilteredSort(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	first := addTestBookmark(t, db, "https://first.example")
+	second := addTestBookmark(t, db, "https://second.example")
+	third := addTestBookmark(t, db, "https://third.example")
+	for _, update := range []struct {
+		id               int64
+		title            string
+		created, updated string
+	}{
+		{first.ID, "beta", "2999-01-01 00:00:00", "2020-01-01 00:00:00"},
+		{second.ID, "Alpha", "2000-01-01 00:00:00", "2022-01-01 00:00:00"},
+		{third.ID, "gamma", "2000-01-01 00:00:00", "2021-01-01 00:00:00"},
+	} {
+		_, err := db.db.Exec(`UPDATE bookmarks SET title = ?, created_at = ?, updated_at = ? WHERE id = ?`,
+			update.title, update.created, update.updated, update.id)
+		if err != nil {
+			t.Fatalf("dating bookmark %d: %v", update.id, err)
+		}
+	}
+
+	tests := []struct {
+		sort models.SortField
+		desc bool
+		want []string
+	}{
+		{"", false, []string{"https://first.example", "https://second.example", "https://third.example"}},
+		{models.SortByID, true, []string{"https://third.example", "https://second.example", "https://first.example"}},
+		// Equal creation times keep ID order in either direction
+		{models.SortByCreated, false, []string{"https://second.example", "https://third.example", "https://first.example"}},
+		{models.SortByCreated, true, []string{"https://first.example", "https://second.example", "https://third.example"}},
+		{models.SortByUpdated, true, []string{"https://second.example", "https://third.example", "https://first.example"}},
+		{models.SortByTitle, false, []string{"https://second.example", "https://first.example", "https://third.example"}},
+		{models.SortByURL, false, []string{"https://first.example", "https://second.example", "https://third.example"}},
+	}
+	for _, tt := range tests {
+		filter := models.BookmarkFilter{Sort: tt.sort, SortDesc: tt.desc}
+		bookmarks, err := db.ListFiltered(ctx, filter, 100, 0)
+		if err != nil {
+			t.Fatalf("ListFiltered(%q): %v", tt.sort, err)
+		}
+		var got []string
+		for _, bookmark := range bookmarks {
+			got = append(got, bookmark.URL)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sort %q desc=%t: got %v, want %v", tt.sort, tt.desc, got, tt.want)
+		}
+	}
+
+	// A sort order replaces search relevance
+	filter := models.BookmarkFilter{Sort: models.SortByTitle, SortDesc: true}
+	if got := searchURLs(t, db, "example", filter); !reflect.DeepEqual(got, []string{"https://third.example", "https://first.example", "https://second.example"}) {
+		t.Errorf("search sorted by title desc = %v", got)
+	}
+
+	if _, err := db.ListFiltered(ctx, models.BookmarkFilter{Sort: "url; DROP TABLE bookmarks"}, 100, 0); err == nil {
+		t.Error("ListFiltered with an unknown sort field succeeded")
+	}
+}
+
+func TestListAndSearchWithCount(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	for i := 0; i < 5; i++ {
+		addTestBookmark(t, db, fmt.Sprintf("https://go.example/%d", i), "go")
+	}
+	addTestBookmark(t, db, "https://rust.example", "rust")
+
+	filter := models.BookmarkFilter{Tags: []string{"go"}}
+	page, total, err := db.ListWithCount(ctx, filter, 2, 4)
+	if err != nil {
+		t.Fatalf("ListWithCount: %v", err)
+	}
+	if total != 5 || len(page) != 1 || page[0].URL != "https://go.example/4" {
+		t.Errorf("ListWithCount = %d bookmark(s) of %d, want go.example/4 of 5", len(page), total)
+	}
+
+	page, total, err = db.SearchWithCount(ctx, "example", filter, 2, 0)
+	if err != nil {
+		t.Fatalf("SearchWithCount: %v", err)
+	}
+	if total != 5 || len(page) != 2 {
+		t.Errorf("SearchWithCount = %d bookmark(s) of %d, want 2 of 5", len(page), total)
+	}
+
+	if _, total, _ := db.SearchWithCount(ctx, "nothing-matches-this", models.BookmarkFilter{}, 10, 0); total != 0 {
+		t.Errorf("SearchWithCount for no matches = %d, want 0", total)
+	}
+}
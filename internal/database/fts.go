@@ -0,0 +1,97 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// The bookmarks_fts table indexes the url, title, description and tags of every
+// bookmark for full-text search. It is an external-content FTS5 table: it stores only
+// the index, and triggers keep it in step with the bookmarks table.
+//
+// go-sqlite3 only includes FTS5 when built with the sqlite_fts5 tag. Without it,
+// Search falls back to LIKE matching, and the triggers are dropped so that a database
+// indexed by an FTS5 build stays writable.
+
+// ftsTriggers are the names of the triggers that keep bookmarks_fts up to date.
+var ftsTriggers = []string{"bookmarks_fts_ai", "bookmarks_fts_ad", "bookmarks_fts_au"}
+
+// initFTS creates the full-text index and its triggers if SQLite supports FTS5 and
+// records whether Search can use it. The index is rebuilt whenever its triggers were
+// missing, as they are the first time and after a build without FTS5 wrote to the
+// database.
+func (d *Database) initFTS() error {
+	_, err := d.db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS bookmarks_fts USING fts5(
+		url, title, description, tags,
+		content='bookmarks', content_rowid='id'
+	)`)
+	if err != nil && strings.Contains(err.Error(), "no such module: fts5") {
+		log.Printf("SQLite was built without FTS5; search falls back to LIKE matching")
+		for _, trigger := range ftsTriggers {
+			if _, err := d.db.Exec(`DROP TRIGGER IF EXISTS ` + trigger); err != nil {
+				return fmt.Errorf("failed to drop full-text trigger %s: %w", trigger, err)
+			}
+		}
+		d.fts = false
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create full-text index: %w", err)
+	}
+
+	var existing int
+	err = d.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?, ?, ?)`,
+		ftsTriggers[0], ftsTriggers[1], ftsTriggers[2]).Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("failed to look up full-text triggers: %w", err)
+	}
+
+	queries := []string{
+		`CREATE TRIGGER IF NOT EXISTS bookmarks_fts_ai AFTER INSERT ON bookmarks BEGIN
+			INSERT INTO bookmarks_fts (rowid, url, title, description, tags)
+			VALUES (new.id, new.url, new.title, new.description, new.tags);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS bookmarks_fts_ad AFTER DELETE ON bookmarks BEGIN
+			INSERT INTO bookmarks_fts (bookmarks_fts, rowid, url, title, description, tags)
+			VALUES ('delete', old.id, old.url, old.title, old.description, old.tags);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS bookmarks_fts_au AFTER UPDATE OF url, title, description, tags ON bookmarks BEGIN
+			INSERT INTO bookmarks_fts (bookmarks_fts, rowid, url, title, description, tags)
+			VALUES ('delete', old.id, old.url, old.title, old.description, old.tags);
+			INSERT INTO bookmarks_fts (rowid, url, title, description, tags)
+			VALUES (new.id, new.url, new.title, new.description, new.tags);
+		END`,
+	}
+	for _, query := range queries {
+		if _, err := d.db.Exec(query); err != nil {
+			return fmt.Errorf("failed to create full-text trigger: %w", err)
+		}
+	}
+
+	if existing < len(ftsTriggers) {
+		if _, err := d.db.Exec(`INSERT INTO bookmarks_fts (bookmarks_fts) VALUES ('rebuild')`); err != nil {
+			return fmt.Errorf("failed to build full-text index: %w", err)
+		}
+	}
+
+	d.fts = true
+	return nil
+}
+
+// ftsQuery turns a user's search text into an FTS5 query matching bookmarks that
+// contain every word, each as a prefix, so "postgres ind" finds "PostgreSQL
+// indexes". Words are quoted, so FTS5 operators and punctuation are taken literally.
+// Words without letters or digits match nothing in the index and are left out; it
+// returns "" if none remain.
+func ftsQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
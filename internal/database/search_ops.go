@@ -3,40 +3,94 @@ package database
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/fallrising/goku-cli/pkg/models"
 )
 
+// ftsRank scores a full-text match with bm25, weighting the url, title, description
+// and tags columns as the LIKE ranking orders them: title, then URL, then tags. Lower
+// is better.
+const ftsRank = `bm25(bookmarks_fts, 3.0, 4.0, 1.0, 2.0)`
+
 func (d *Database) Search(ctx context.Context, query string, limit, offset int) ([]*models.Bookmark, error) {
-	searchQuery := `
-		SELECT id, url, title, description, tags, created_at, updated_at 
-		FROM bookmarks 
-		WHERE url LIKE ? OR title LIKE ? OR description LIKE ? OR tags LIKE ?
-		LIMIT ? OFFSET ?
-	`
-	searchParam := "%" + query + "%"
+	return d.SearchFiltered(ctx, query, models.BookmarkFilter{}, limit, offset)
+}
 
-	rows, err := d.db.QueryContext(ctx, searchQuery, searchParam, searchParam, searchParam, searchParam, limit, offset)
+// SearchFiltered returns the bookmarks matching query and filter, best matches first
+// unless filter sets a sort order. With the full-text index, every word of query must
+// start a word of the bookmark's URL, title, description or tags; without it, query
+// must appear in one of them as typed.
+func (d *Database) SearchFiltered(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, error) {
+	source, args, order, orderArgs, err := d.searchSource(query, filter)
+	if err != nil {
+		return nil, err
+	}
+	args = append(append(args, orderArgs...), limit, offset)
+	bookmarks, err := d.queryBookmarks(ctx, d.db, `SELECT `+bookmarkColumns+source+order+` LIMIT ? OFFSET ?`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search bookmarks: %w", err)
 	}
-	defer rows.Close()
-
-	var bookmarks []*models.Bookmark
-	for rows.Next() {
-		var bookmark models.Bookmark
-		var tags string
-		err := rows.Scan(
-			&bookmark.ID, &bookmark.URL, &bookmark.Title, &bookmark.Description,
-			&tags, &bookmark.CreatedAt, &bookmark.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan bookmark row: %w", err)
+	return bookmarks, nil
+}
+
+// SearchWithCount returns the same page as SearchFiltered along with the number of
+// bookmarks matching query and filter in all.
+func (d *Database) SearchWithCount(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error) {
+	source, args, order, orderArgs, err := d.searchSource(query, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	bookmarks, total, err := d.pageWithCount(ctx, source, args, order, orderArgs, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search bookmarks: %w", err)
+	}
+	return bookmarks, total, nil
+}
+
+// searchSource returns the FROM and WHERE clauses selecting the bookmarks that match
+// query and filter, and the ORDER BY clause ranking them, each with its arguments.
+// It uses the full-text index when there is one and query has words it can match,
+// and LIKE otherwise. A sort order set in filter replaces the ranking.
+func (d *Database) searchSource(query string, filter models.BookmarkFilter) (source string, args []interface{}, order string, orderArgs []interface{}, err error) {
+	where, filterArgs := buildFilterClause(filter, d.relationalTags)
+	if where != "" {
+		where = ` AND ` + where
+	}
+	if filter.Sort != "" {
+		if order, err = orderClause(filter.Sort, filter.SortDesc); err != nil {
+			return "", nil, "", nil, err
 		}
-		bookmark.Tags = strings.Split(tags, ",")
-		bookmarks = append(bookmarks, &bookmark)
 	}
 
-	return bookmarks, nil
+	if match := ftsQuery(query); d.fts && match != "" {
+		source = `
+			FROM (SELECT rowid AS match_id, ` + ftsRank + ` AS rank FROM bookmarks_fts WHERE bookmarks_fts MATCH ?) AS matches
+			JOIN bookmarks ON bookmarks.id = matches.match_id
+			WHERE 1 = 1` + where
+		if order == "" {
+			order = ` ORDER BY matches.rank, bookmarks.id`
+		}
+		return source, append([]interface{}{match}, filterArgs...), order, nil, nil
+	}
+
+	source = `
+		FROM bookmarks
+		WHERE (url LIKE ? OR title LIKE ? OR description LIKE ? OR tags LIKE ?)` + where
+	searchParam := "%" + query + "%"
+	args = append([]interface{}{searchParam, searchParam, searchParam, searchParam}, filterArgs...)
+	if order != "" {
+		return source, args, order, nil, nil
+	}
+
+	order = `
+		ORDER BY
+			CASE
+				WHEN title LIKE ? THEN 4
+				WHEN url LIKE ? THEN 3
+				WHEN tags LIKE ? THEN 2
+				ELSE 1
+			END DESC,
+			id`
+	// Title matches rank above URL, tag, and description matches
+	return source, args, order, []interface{}{searchParam, searchParam, searchParam}, nil
 }
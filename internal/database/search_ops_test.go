@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// searchURLs returns the URLs of the bookmarks SearchFiltered finds for query.
+func searchURLs(t *testing.T, db *Database, query string, filter models.BookmarkFilter) []string {
+	t.Helper()
+	bookmarks, err := db.SearchFiltered(context.Background(), query, filter, 100, 0)
+	if err != nil {
+		t.Fatalf("SearchFiltered(%q): %v", query, err)
+	}
+	var urls []string
+	for _, bookmark := range bookmarks {
+		urls = append(urls, bookmark.URL)
+	}
+	return urls
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	t.Logf("full-text index: %t", db.fts)
+
+	for _, bookmark := range []*models.Bookmark{
+		{URL: "https://blog.example/tuning", Title: "Tuning databases", Description: "Notes on postgres vacuum", Tags: []string{"db"}},
+		{URL: "https://wiki.example/pg", Title: "Postgres indexes", Description: "B-trees and GIN", Tags: []string{"db", "postgres"}},
+		{URL: "https://other.example", Title: "Gardening", Description: "Tomatoes", Tags: []string{"home"}},
+	} {
+		if err := db.Create(ctx, bookmark); err != nil {
+			t.Fatalf("Create(%s): %v", bookmark.URL, err)
+		}
+	}
+
+	// Title matches rank above description matches
+	want := []string{"https://wiki.example/pg", "https://blog.example/tuning"}
+	if got := searchURLs(t, db, "postgres", models.BookmarkFilter{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("search postgres = %v, want %v", got, want)
+	}
+	if got := searchURLs(t, db, "postgres", models.BookmarkFilter{Tags: []string{"postgres"}}); !reflect.DeepEqual(got, want[:1]) {
+		t.Errorf("search postgres tagged postgres = %v, want %v", got, want[:1])
+	}
+
+	// Search syntax in the query is taken literally
+	for _, query := range []string{`"postgres`, "postgres OR", "title:postgres", "NEAR(", "-"} {
+		if _, err := db.SearchFiltered(ctx, query, models.BookmarkFilter{}, 10, 0); err != nil {
+			t.Errorf("SearchFiltered(%q): %v", query, err)
+		}
+	}
+
+	// The index follows updates and deletes
+	garden, _ := db.GetByURL(ctx, "https://other.example")
+	garden.Title = "Postgres in the garden"
+	if err := db.Update(ctx, garden); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got := searchURLs(t, db, "tomatoes", models.BookmarkFilter{}); !reflect.DeepEqual(got, []string{"https://other.example"}) {
+		t.Errorf("search tomatoes after update = %v", got)
+	}
+	if got := searchURLs(t, db, "gardening", models.BookmarkFilter{}); got != nil {
+		t.Errorf("search for the old title = %v, want nothing", got)
+	}
+	if err := db.Delete(ctx, garden.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got := searchURLs(t, db, "garden", models.BookmarkFilter{}); got != nil {
+		t.Errorf("search after delete = %v, want nothing", got)
+	}
+
+	if db.fts {
+		// Every word must start a word of the bookmark, in any order
+		if got := searchURLs(t, db, "index postgre", models.BookmarkFilter{}); !reflect.DeepEqual(got, want[:1]) {
+			t.Errorf("search index postgre = %v, want %v", got, want[:1])
+		}
+
+		// Writes made while the triggers were missing are indexed on the next Init
+		for _, trigger := range ftsTriggers {
+			if _, err := db.db.Exec(`DROP TRIGGER ` + trigger); err != nil {
+				t.Fatalf("dropping %s: %v", trigger, err)
+			}
+		}
+		addTestBookmark(t, db, "https://unindexed.example/zebra")
+		if err := db.initFTS(); err != nil {
+			t.Fatalf("initFTS: %v", err)
+		}
+		if got := searchURLs(t, db, "zebra", models.BookmarkFilter{}); !reflect.DeepEqual(got, []string{"https://unindexed.example/zebra"}) {
+			t.Errorf("search zebra after rebuild = %v", got)
+		}
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"postgres index": `"postgres"* "index"*`,
+		`say "hi"`:       `"say"* """hi"""*`,
+		" - / ":          "",
+		"c++":            `"c++"*`,
+	}
+	for text, want := range tests {
+		if got := ftsQuery(text); got != want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
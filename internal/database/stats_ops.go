@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"github.com/fallrising/goku-cli/pkg/models"
-	"strings"
 )
 
 func (d *Database) CountByHostname(ctx context.Context) (map[string]int, error) {
@@ -18,6 +17,7 @@ func (d *Database) CountByHostname(ctx context.Context) (map[string]int, error)
 		) as hostname, 
 		COUNT(*) as count 
 	FROM bookmarks 
+	WHERE deleted_at IS NULL
 	GROUP BY hostname`
 
 	rows, err := d.db.QueryContext(ctx, query)
@@ -40,28 +40,16 @@ func (d *Database) CountByHostname(ctx context.Context) (map[string]int, error)
 }
 
 func (d *Database) GetLatest(ctx context.Context, limit int) ([]*models.Bookmark, error) {
-	query := `SELECT id, url, title, description, tags, created_at, updated_at 
-	FROM bookmarks 
+	query := `SELECT ` + bookmarkColumns + `
+	FROM bookmarks
+	WHERE deleted_at IS NULL
 	ORDER BY created_at DESC 
 	LIMIT ?`
 
-	rows, err := d.db.QueryContext(ctx, query, limit)
+	bookmarks, err := d.queryBookmarks(ctx, d.db, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query latest bookmarks: %w", err)
 	}
-	defer rows.Close()
-
-	var bookmarks []*models.Bookmark
-	for rows.Next() {
-		var b models.Bookmark
-		var tags string
-		if err := rows.Scan(&b.ID, &b.URL, &b.Title, &b.Description, &tags, &b.CreatedAt, &b.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
-		}
-		b.Tags = strings.Split(tags, ",")
-		bookmarks = append(bookmarks, &b)
-	}
-
 	return bookmarks, nil
 }
 
@@ -73,6 +61,7 @@ func (d *Database) CountAccessibility(ctx context.Context) (map[string]int, erro
 		END as status, 
 		COUNT(*) as count 
 	FROM bookmarks 
+	WHERE deleted_at IS NULL
 	GROUP BY status`
 
 	rows, err := d.db.QueryContext(ctx, query)
@@ -105,6 +94,7 @@ func (d *Database) TopHostnames(ctx context.Context, limit int) ([]models.Hostna
 		) as hostname, 
 		COUNT(*) as count 
 	FROM bookmarks 
+	WHERE deleted_at IS NULL
 	GROUP BY hostname 
 	ORDER BY count DESC 
 	LIMIT ?`
@@ -137,6 +127,7 @@ func (d *Database) ListUniqueHostnames(ctx context.Context) ([]string, error) {
 			end
 		) as hostname
 	FROM bookmarks 
+	WHERE deleted_at IS NULL
 	ORDER BY hostname`
 
 	rows, err := d.db.QueryContext(ctx, query)
@@ -162,7 +153,7 @@ func (d *Database) CountCreatedLastNDays(ctx context.Context, days int) (map[str
 		date(created_at) as day, 
 		COUNT(*) as count 
 	FROM bookmarks 
-	WHERE created_at >= date('now', ?)
+	WHERE deleted_at IS NULL AND created_at >= date('now', ?)
 	GROUP BY day 
 	ORDER BY day DESC`
 
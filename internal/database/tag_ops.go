@@ -2,12 +2,35 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
 )
 
+// ListAllTags returns every distinct tag, sorted. The list is cached for tagListTTL
+// so shell completion doesn't scan every bookmark on each keystroke.
 func (d *Database) ListAllTags(ctx context.Context) ([]string, error) {
-	query := `SELECT tags FROM bookmarks`
+	if tags, ok, err := d.cache.GetTags(ctx, d.tagListCacheKey()); err != nil {
+		return nil, err
+	} else if ok {
+		return tags, nil
+	}
+
+	if d.relationalTags {
+		tags, err := d.listRelationalTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := d.cache.SetTags(ctx, d.tagListCacheKey(), tags, tagListTTL); err != nil {
+			return nil, err
+		}
+		return tags, nil
+	}
+
+	query := `SELECT tags FROM bookmarks WHERE ` + liveExpr
 
 	rows, err := d.db.QueryContext(ctx, query)
 	if err != nil {
@@ -37,20 +60,25 @@ func (d *Database) ListAllTags(ctx context.Context) ([]string, error) {
 	for tag := range tagSet {
 		uniqueTags = append(uniqueTags, tag)
 	}
+	sort.Strings(uniqueTags)
+
+	if err := d.cache.SetTags(ctx, d.tagListCacheKey(), uniqueTags, tagListTTL); err != nil {
+		return nil, err
+	}
 
 	return uniqueTags, nil
 }
 
+// CountByTag returns how many live bookmarks carry each tag. Untagged bookmarks are
+// not counted.
 func (d *Database) CountByTag(ctx context.Context) (map[string]int, error) {
-	query := `SELECT tag, COUNT(*) as count 
-	FROM (
-		SELECT trim(value) as tag
-		FROM bookmarks
-		CROSS JOIN json_each('["' || replace(replace(tags, ' ', ''), ',', '","') || '"]')
-	)
-	GROUP BY tag`
+	if d.relationalTags {
+		return d.countRelationalTags(ctx)
+	}
 
-	rows, err := d.db.QueryContext(ctx, query)
+	// Split in Go rather than SQL: tags may contain spaces, quotes and other characters
+	// that string-built JSON or SQL splitting would mangle
+	rows, err := d.db.QueryContext(ctx, `SELECT tags FROM bookmarks WHERE `+liveExpr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tags: %w", err)
 	}
@@ -58,13 +86,205 @@ func (d *Database) CountByTag(ctx context.Context) (map[string]int, error) {
 
 	counts := make(map[string]int)
 	for rows.Next() {
-		var tag string
-		var count int
-		if err := rows.Scan(&tag, &count); err != nil {
-			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		var raw sql.NullString
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("failed to scan tags: %w", err)
+		}
+		// A tag repeated on one bookmark counts once, as with relational tags
+		seen := make(map[string]struct{})
+		for _, tag := range strings.Split(raw.String, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+			seen[tag] = struct{}{}
+			counts[tag]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// MergeTags replaces every tag in from with into across all bookmarks in a single
+// transaction. It returns how many bookmarks carried each source tag.
+// Tags are compared trimmed and lowercased, the same way Bookmark.AddTag stores them.
+func (d *Database) MergeTags(ctx context.Context, from []string, into string) (map[string]int, error) {
+	into = strings.ToLower(strings.TrimSpace(into))
+	sources := make(map[string]struct{})
+	for _, tag := range from {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" {
+			sources[tag] = struct{}{}
+		}
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, tags FROM bookmarks`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks for tags: %w", err)
+	}
+
+	counts := make(map[string]int)
+	updates := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var tags string
+		if err := rows.Scan(&id, &tags); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan tags: %w", err)
+		}
+
+		var merged []string
+		seen := make(map[string]struct{})
+		changed := false
+		for _, tag := range strings.Split(tags, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			key := strings.ToLower(tag)
+			if _, ok := sources[key]; ok {
+				counts[key]++
+				tag, key = into, into
+				changed = true
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			merged = append(merged, tag)
+		}
+		if changed {
+			updates[id] = strings.Join(merged, ",")
 		}
-		counts[tag] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookmark rows: %w", err)
+	}
+
+	for id, tags := range updates {
+		_, err := tx.ExecContext(ctx, `UPDATE bookmarks SET tags = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, tags, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update tags for bookmark %d: %w", id, err)
+		}
+		if err := syncBookmarkTags(ctx, tx, id, strings.Split(tags, ",")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Drop stale cache entries only once the new tags are committed
+	for id := range updates {
+		if err := d.cache.Delete(ctx, d.cacheKey(id)); err != nil {
+			return nil, fmt.Errorf("failed to delete cached bookmark: %w", err)
+		}
+	}
+	if err := d.invalidateTagList(ctx); err != nil {
+		return nil, err
 	}
 
 	return counts, nil
 }
+
+// NormalizeTags rewrites the tags of every bookmark through models.NormalizeTags in a
+// single transaction and returns how many bookmarks changed. The relational tag
+// tables are rebuilt from the result, so tag names there are normalized too. With
+// dryRun the bookmarks are only counted.
+func (d *Database) NormalizeTags(ctx context.Context, dryRun bool) (int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, tags FROM bookmarks ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query bookmarks for tags: %w", err)
+	}
+
+	type bookmarkTags struct {
+		id      int64
+		tags    []string
+		changed bool
+	}
+	var all []bookmarkTags
+	changed := 0
+	for rows.Next() {
+		var id int64
+		var raw sql.NullString
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tags: %w", err)
+		}
+		tags := models.NormalizeTags(strings.Split(raw.String, ","))
+		isChanged := strings.Join(tags, ",") != raw.String
+		if isChanged {
+			changed++
+		}
+		all = append(all, bookmarkTags{id, tags, isChanged})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating bookmark rows: %w", err)
+	}
+
+	if dryRun {
+		return changed, nil
+	}
+
+	for _, bookmark := range all {
+		if !bookmark.changed {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE bookmarks SET tags = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			strings.Join(bookmark.tags, ","), bookmark.id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update tags for bookmark %d: %w", bookmark.id, err)
+		}
+	}
+
+	// Tag rows keep the spelling they were first inserted with, so start them afresh
+	for _, query := range []string{`DELETE FROM bookmark_tags`, `DELETE FROM tags`} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return 0, fmt.Errorf("failed to clear tag tables: %w", err)
+		}
+	}
+	for _, bookmark := range all {
+		if _, err := linkBookmarkTags(ctx, tx, bookmark.id, bookmark.tags); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, bookmark := range all {
+		if !bookmark.changed {
+			continue
+		}
+		if err := d.cache.Delete(ctx, d.cacheKey(bookmark.id)); err != nil {
+			return 0, fmt.Errorf("failed to delete cached bookmark: %w", err)
+		}
+	}
+	if err := d.invalidateTagList(ctx); err != nil {
+		return 0, err
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// newTestDatabase returns an initialized Database backed by files in a temp directory.
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := NewDatabase(filepath.Join(dir, "test.db"), filepath.Join(dir, "test_cache.db"), "test", DefaultConnectionOptions)
+	if err != nil {
+		t.Fatalf("NewDatabase: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// addTestBookmark stores a bookmark with the given URL and tags.
+func addTestBookmark(t *testing.T, db *Database, url string, tags ...string) *models.Bookmark {
+	t.Helper()
+	bookmark := &models.Bookmark{URL: url, Title: url, Tags: tags}
+	if err := db.Create(context.Background(), bookmark); err != nil {
+		t.Fatalf("Create(%s): %v", url, err)
+	}
+	return bookmark
+}
+
+func TestMergeTags(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	first := addTestBookmark(t, db, "https://a.example", "JS", "web")
+	second := addTestBookmark(t, db, "https://b.example", "javascript", "js")
+	third := addTestBookmark(t, db, "https://c.example", "go")
+
+	// The service rejects a source equal to the target, so only distinct tags are merged
+	counts, err := db.MergeTags(ctx, []string{" JS "}, "javascript")
+	if err != nil {
+		t.Fatalf("MergeTags: %v", err)
+	}
+
+	wantCounts := map[string]int{"js": 2}
+	if !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("counts = %v, want %v", counts, wantCounts)
+	}
+
+	tests := []struct {
+		id   int64
+		want []string
+	}{
+		{first.ID, []string{"javascript", "web"}},
+		{second.ID, []string{"javascript"}},
+		{third.ID, []string{"go"}},
+	}
+	for _, tt := range tests {
+		bookmark, err := db.GetByID(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetByID(%d): %v", tt.id, err)
+		}
+		if !reflect.DeepEqual(bookmark.Tags, tt.want) {
+			t.Errorf("bookmark %d tags = %v, want %v", tt.id, bookmark.Tags, tt.want)
+		}
+	}
+}
+
+func TestListAllTagsCache(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	addTestBookmark(t, db, "https://a.example", "web", "go")
+	tags, err := db.ListAllTags(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTags: %v", err)
+	}
+	if want := []string{"go", "web"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("tags = %v, want %v", tags, want)
+	}
+
+	// A write the cache doesn't see is hidden until the entry is dropped
+	if _, err := db.db.Exec(`UPDATE bookmarks SET tags = 'rust'`); err != nil {
+		t.Fatalf("updating tags: %v", err)
+	}
+	if tags, _ := db.ListAllTags(ctx); !reflect.DeepEqual(tags, []string{"go", "web"}) {
+		t.Errorf("tags after raw update = %v, want the cached list", tags)
+	}
+
+	addTestBookmark(t, db, "https://b.example", "zig")
+	tags, err = db.ListAllTags(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTags: %v", err)
+	}
+	if want := []string{"rust", "zig"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags after Create = %v, want %v", tags, want)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	messy := addTestBookmark(t, db, "https://a.example", "Go")
+	clean := addTestBookmark(t, db, "https://b.example", "go", "web dev")
+	empty := addTestBookmark(t, db, "https://c.example")
+	if _, err := db.db.Exec(`UPDATE bookmarks SET tags = ? WHERE id = ?`, " Go,,GO, Web   Dev ,", messy.ID); err != nil {
+		t.Fatalf("writing messy tags: %v", err)
+	}
+
+	changed, err := db.NormalizeTags(ctx, true)
+	if err != nil {
+		t.Fatalf("NormalizeTags(dry run): %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("dry run counted %d changed bookmarks, want 1", changed)
+	}
+	var stored string
+	if err := db.db.QueryRow(`SELECT tags FROM bookmarks WHERE id = ?`, messy.ID).Scan(&stored); err != nil || stored != " Go,,GO, Web   Dev ," {
+		t.Errorf("dry run changed tags to %q (%v)", stored, err)
+	}
+
+	changed, err = db.NormalizeTags(ctx, false)
+	if err != nil {
+		t.Fatalf("NormalizeTags: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("changed %d bookmarks, want 1", changed)
+	}
+
+	tests := []struct {
+		id   int64
+		want []string
+	}{
+		{messy.ID, []string{"go", "web dev"}},
+		{clean.ID, []string{"go", "web dev"}},
+		{empty.ID, nil},
+	}
+	for _, tt := range tests {
+		bookmark, err := db.GetByID(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetByID(%d): %v", tt.id, err)
+		}
+		var got []string
+		for _, tag := range bookmark.Tags {
+			if tag != "" {
+				got = append(got, tag)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("bookmark %d tags = %q, want %q", tt.id, bookmark.Tags, tt.want)
+		}
+	}
+
+	// The relational tables were first filled with the messy spelling
+	db.SetRelationalTags(true)
+	tags, err := db.ListAllTags(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTags: %v", err)
+	}
+	if want := []string{"go", "web dev"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("relational tags = %q, want %q", tags, want)
+	}
+
+	if changed, _ := db.NormalizeTags(ctx, false); changed != 0 {
+		t.Errorf("second run changed %d bookmarks, want 0", changed)
+	}
+}
+
+func TestCountByTag(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	addTestBookmark(t, db, "https://a.example", "machine learning", "c++")
+	addTestBookmark(t, db, "https://b.example", " machine learning ", `say "hi"`, "c++")
+	addTestBookmark(t, db, "https://c.example", "go", "go")
+	addTestBookmark(t, db, "https://d.example")
+	trashed := addTestBookmark(t, db, "https://e.example", "go")
+	if err := db.Delete(ctx, trashed.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	counts, err := db.CountByTag(ctx)
+	if err != nil {
+		t.Fatalf("CountByTag: %v", err)
+	}
+	want := map[string]int{"machine learning": 2, "c++": 2, `say "hi"`: 1, "go": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,307 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// The tags and bookmark_tags tables hold the same tags as the comma-separated tags
+// column, one row per tag and per bookmark/tag pair. Every write keeps both in step;
+// SetRelationalTags decides which of them bookmarks' tags, filters and tag listings
+// are read from. The column stays up to date for the full-text index.
+
+// tagSchemaVersion is the PRAGMA user_version at which existing comma tags have
+// been copied into the relational tables.
+const tagSchemaVersion = 1
+
+// relationalTagMatchExpr matches bookmarks linked to a tag. The NOCASE collation on
+// tags.name keeps matching case-insensitive, as with tagMatchExpr.
+const relationalTagMatchExpr = `EXISTS (SELECT 1 FROM bookmark_tags bt JOIN tags t ON t.id = bt.tag_id
+	WHERE bt.bookmark_id = bookmarks.id AND t.name = ?)`
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SetRelationalTags makes the tags of read bookmarks, tag filters, tag listings and
+// tag counts come from the tags/bookmark_tags tables instead of the tags column.
+func (d *Database) SetRelationalTags(enabled bool) {
+	d.relationalTags = enabled
+}
+
+// initTagTables creates the relational tag tables and, the first time, fills them
+// from the tags column.
+func (d *Database) initTagTables() error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE
+		)`,
+		`CREATE TABLE IF NOT EXISTS bookmark_tags (
+			bookmark_id INTEGER NOT NULL REFERENCES bookmarks(id) ON DELETE CASCADE,
+			tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+			PRIMARY KEY (bookmark_id, tag_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_bookmark_tags_tag_id ON bookmark_tags(tag_id)`,
+	}
+	for _, query := range queries {
+		if _, err := d.db.Exec(query); err != nil {
+			return fmt.Errorf("failed to create tag tables: %w", err)
+		}
+	}
+
+	var version int
+	if err := d.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version >= tagSchemaVersion {
+		return nil
+	}
+
+	if _, err := d.MigrateTagsToRelational(context.Background()); err != nil {
+		return err
+	}
+	if _, err := d.db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, tagSchemaVersion)); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	return nil
+}
+
+// MigrateTagsToRelational rebuilds bookmark_tags from the tags column of every
+// bookmark. It returns the number of bookmarks that have at least one tag.
+func (d *Database) MigrateTagsToRelational(ctx context.Context) (int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	tagged, err := relinkAllTags(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return tagged, nil
+}
+
+// relinkAllTags rebuilds bookmark_tags in tx from the tags column of every bookmark
+// and returns the number of bookmarks that have at least one tag.
+func relinkAllTags(ctx context.Context, tx *sql.Tx) (int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, tags FROM bookmarks ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query bookmarks for tags: %w", err)
+	}
+	// Kept in ID order so a tag's first spelling wins, as it would have on insert
+	type bookmarkTags struct {
+		id   int64
+		tags []string
+	}
+	var all []bookmarkTags
+	for rows.Next() {
+		var id int64
+		var tags sql.NullString
+		if err := rows.Scan(&id, &tags); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan tags: %w", err)
+		}
+		all = append(all, bookmarkTags{id, strings.Split(tags.String, ",")})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating bookmark rows: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bookmark_tags`); err != nil {
+		return 0, fmt.Errorf("failed to clear bookmark tags: %w", err)
+	}
+	tagged := 0
+	for _, bookmark := range all {
+		linked, err := linkBookmarkTags(ctx, tx, bookmark.id, bookmark.tags)
+		if err != nil {
+			return 0, err
+		}
+		if linked > 0 {
+			tagged++
+		}
+	}
+	return tagged, nil
+}
+
+// syncBookmarkTags replaces the links of bookmarkID with tags.
+func syncBookmarkTags(ctx context.Context, db execer, bookmarkID int64, tags []string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM bookmark_tags WHERE bookmark_id = ?`, bookmarkID); err != nil {
+		return fmt.Errorf("failed to unlink tags of bookmark %d: %w", bookmarkID, err)
+	}
+	_, err := linkBookmarkTags(ctx, db, bookmarkID, tags)
+	return err
+}
+
+// linkBookmarkTags links bookmarkID to each non-empty tag, creating tags that don't
+// exist yet. It returns the number of tags linked.
+func linkBookmarkTags(ctx context.Context, db execer, bookmarkID int64, tags []string) (int, error) {
+	linked := 0
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag); err != nil {
+			return linked, fmt.Errorf("failed to insert tag %q: %w", tag, err)
+		}
+		_, err := db.ExecContext(ctx, `INSERT OR IGNORE INTO bookmark_tags (bookmark_id, tag_id)
+			SELECT ?, id FROM tags WHERE name = ?`, bookmarkID, tag)
+		if err != nil {
+			return linked, fmt.Errorf("failed to link tag %q to bookmark %d: %w", tag, bookmarkID, err)
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+// tagLoadChunk is how many bookmarks loadRelationalTags looks up per query, well
+// below SQLite's limit on bound parameters.
+const tagLoadChunk = 500
+
+// loadRelationalTags replaces the Tags of bookmarks with the tags they are linked to,
+// in the order they were given, if relational tags are enabled.
+func (d *Database) loadRelationalTags(ctx context.Context, db queryer, bookmarks []*models.Bookmark) error {
+	if !d.relationalTags || len(bookmarks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*models.Bookmark, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		bookmark.Tags = []string{}
+		byID[bookmark.ID] = bookmark
+	}
+
+	for start := 0; start < len(bookmarks); start += tagLoadChunk {
+		chunk := bookmarks[start:min(start+tagLoadChunk, len(bookmarks))]
+		args := make([]interface{}, len(chunk))
+		for i, bookmark := range chunk {
+			args[i] = bookmark.ID
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chunk)), ", ")
+
+		// Links are inserted in tag order, so rowid order is the order of the tags
+		rows, err := db.QueryContext(ctx, `SELECT bt.bookmark_id, t.name FROM bookmark_tags bt
+			JOIN tags t ON t.id = bt.tag_id
+			WHERE bt.bookmark_id IN (`+placeholders+`)
+			ORDER BY bt.rowid`, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query bookmark tags: %w", err)
+		}
+		for rows.Next() {
+			var id int64
+			var tag string
+			if err := rows.Scan(&id, &tag); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan bookmark tag: %w", err)
+			}
+			byID[id].Tags = append(byID[id].Tags, tag)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating bookmark tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// listRelationalTags returns the names of tags linked to at least one bookmark
+// outside the trash.
+func (d *Database) listRelationalTags(ctx context.Context) ([]string, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT t.name FROM tags t
+		WHERE EXISTS (SELECT 1 FROM bookmark_tags bt JOIN bookmarks b ON b.id = bt.bookmark_id
+			WHERE bt.tag_id = t.id AND b.deleted_at IS NULL)
+		ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// countRelationalTags returns the number of bookmarks outside the trash linked to
+// each tag.
+func (d *Database) countRelationalTags(ctx context.Context) (map[string]int, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT t.name, COUNT(*) FROM tags t
+		JOIN bookmark_tags bt ON bt.tag_id = t.id
+		JOIN bookmarks b ON b.id = bt.bookmark_id
+		WHERE b.deleted_at IS NULL
+		GROUP BY t.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tag string
+		var count int
+		if err := rows.Scan(&tag, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag count: %w", err)
+		}
+		counts[tag] = count
+	}
+	return counts, rows.Err()
+}
+
+// PruneTags removes tags that no bookmark is linked to any more, e.g. after the last
+// bookmark carrying them was purged from the trash or retagged, and returns their
+// names. Tags of bookmarks in the trash are kept for when they are restored. With
+// dryRun the tags are only listed.
+func (d *Database) PruneTags(ctx context.Context, dryRun bool) ([]string, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const orphaned = `NOT EXISTS (SELECT 1 FROM bookmark_tags bt WHERE bt.tag_id = tags.id)`
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM tags WHERE `+orphaned+` ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphaned tags: %w", err)
+	}
+	var pruned []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		pruned = append(pruned, tag)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag rows: %w", err)
+	}
+
+	if dryRun || len(pruned) == 0 {
+		return pruned, nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE `+orphaned); err != nil {
+		return nil, fmt.Errorf("failed to delete orphaned tags: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateTagsToRelational(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	// Bookmarks written before the relational tables existed
+	for _, tags := range []string{"go,Web", "web, rust", ""} {
+		if _, err := db.db.Exec(`INSERT INTO bookmarks (url, title, tags) VALUES ('https://example.com', 'x', ?)`, tags); err != nil {
+			t.Fatalf("inserting bookmark: %v", err)
+		}
+	}
+	if _, err := db.db.Exec(`PRAGMA user_version = 0`); err != nil {
+		t.Fatalf("resetting schema version: %v", err)
+	}
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	db.SetRelationalTags(true)
+	counts, err := db.CountByTag(ctx)
+	if err != nil {
+		t.Fatalf("CountByTag: %v", err)
+	}
+	if want := map[string]int{"go": 1, "Web": 2, "rust": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
+
+func TestRelationalTagsFollowWrites(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	db.SetRelationalTags(true)
+
+	first := addTestBookmark(t, db, "https://a.example", "js", "web")
+	second := addTestBookmark(t, db, "https://b.example", "javascript")
+
+	first.Tags = []string{"js", "go"}
+	if err := db.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := db.MergeTags(ctx, []string{"js"}, "javascript"); err != nil {
+		t.Fatalf("MergeTags: %v", err)
+	}
+	if err := db.Delete(ctx, second.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	counts, err := db.CountByTag(ctx)
+	if err != nil {
+		t.Fatalf("CountByTag: %v", err)
+	}
+	if want := map[string]int{"javascript": 1, "go": 1}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	tags, err := db.ListAllTags(ctx)
+	if err != nil {
+		t.Fatalf("ListAllTags: %v", err)
+	}
+	if want := []string{"go", "javascript"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+}
+
+func TestRelationalTagsAreRead(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	db.SetRelationalTags(true)
+
+	// The tags column cannot tell a comma inside a tag from a separator
+	tagged := addTestBookmark(t, db, "https://a.example", "web", "c, c++", "go")
+	untagged := addTestBookmark(t, db, "https://b.example")
+	if err := db.cache.Clear(ctx); err != nil {
+		t.Fatalf("clearing cache: %v", err)
+	}
+
+	got, err := db.GetByID(ctx, tagged.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if want := []string{"web", "c, c++", "go"}; !reflect.DeepEqual(got.Tags, want) {
+		t.Errorf("GetByID tags = %q, want %q", got.Tags, want)
+	}
+
+	listed, err := db.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(listed) != 2 || !reflect.DeepEqual(listed[0].Tags, got.Tags) || len(listed[1].Tags) != 0 {
+		t.Errorf("List = %+v, want %q and no tags for bookmark %d", listed, got.Tags, untagged.ID)
+	}
+
+	db.SetRelationalTags(false)
+	listed, err = db.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := []string{"web", "c", " c++", "go"}; !reflect.DeepEqual(listed[0].Tags, want) {
+		t.Errorf("List tags from column = %q, want %q", listed[0].Tags, want)
+	}
+}
+
+func TestPruneTags(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	first := addTestBookmark(t, db, "https://a.example", "go", "web")
+	second := addTestBookmark(t, db, "https://b.example", "rust")
+
+	first.Tags = []string{"go"}
+	if err := db.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := db.Delete(ctx, second.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// The trashed bookmark keeps its tags until the trash is emptied
+	if got, err := db.PruneTags(ctx, true); err != nil || !reflect.DeepEqual(got, []string{"web"}) {
+		t.Fatalf("PruneTags(dry run) with bookmark in trash = %v, %v; want [web]", got, err)
+	}
+	if _, err := db.PurgeDeleted(ctx); err != nil {
+		t.Fatalf("PurgeDeleted: %v", err)
+	}
+
+	want := []string{"rust", "web"}
+	if got, err := db.PruneTags(ctx, true); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("PruneTags(dry run) = %v, %v; want %v", got, err, want)
+	}
+	if got, err := db.PruneTags(ctx, false); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("PruneTags = %v, %v; want %v", got, err, want)
+	}
+	if got, err := db.PruneTags(ctx, true); err != nil || len(got) != 0 {
+		t.Errorf("PruneTags after pruning = %v, %v; want none", got, err)
+	}
+}
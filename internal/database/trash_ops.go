@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// Deleting a bookmark moves it to the trash by setting deleted_at. The row keeps its
+// tags and full-text index entry so Restore can bring it back as it was; queries for
+// live bookmarks filter on liveExpr.
+
+// ListDeleted returns the bookmarks in the trash, most recently deleted first.
+func (d *Database) ListDeleted(ctx context.Context, limit, offset int) ([]*models.Bookmark, error) {
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?`
+	return d.queryBookmarks(ctx, d.db, query, limit, offset)
+}
+
+// Restore takes a bookmark out of the trash. It fails with ErrDuplicateURL if its URL
+// has been bookmarked again since it was deleted.
+func (d *Database) Restore(ctx context.Context, id int64) error {
+	query := `SELECT ` + bookmarkColumns + ` FROM bookmarks WHERE id = ? AND deleted_at IS NOT NULL`
+	bookmark, err := scanBookmark(d.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("bookmark %d is not in the trash", id)
+		}
+		return fmt.Errorf("failed to get deleted bookmark: %w", err)
+	}
+
+	existing, err := d.GetByURL(ctx, bookmark.URL)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%w: bookmark %d has URL %s", ErrDuplicateURL, existing.ID, bookmark.URL)
+	}
+
+	_, err = d.db.ExecContext(ctx, `UPDATE bookmarks SET deleted_at = NULL WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore bookmark: %w", err)
+	}
+
+	err = d.cache.AddURL(ctx, bookmark.URL)
+	if err != nil {
+		return fmt.Errorf("failed to add URL to cache set: %w", err)
+	}
+
+	return d.invalidateTagList(ctx)
+}
+
+// PurgeDeleted permanently removes every bookmark in the trash and returns how many
+// there were.
+func (d *Database) PurgeDeleted(ctx context.Context) (int, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM bookmark_tags WHERE bookmark_id IN (SELECT id FROM bookmarks WHERE deleted_at IS NOT NULL)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unlink tags of deleted bookmarks: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM bookmarks WHERE deleted_at IS NOT NULL`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted bookmarks: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int(purged), nil
+}
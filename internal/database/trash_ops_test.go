@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fallrising/goku-cli/pkg/models"
+)
+
+// bookmarkIDs returns the IDs of bookmarks, in order.
+func bookmarkIDs(bookmarks []*models.Bookmark) []int64 {
+	var ids []int64
+	for _, bookmark := range bookmarks {
+		ids = append(ids, bookmark.ID)
+	}
+	return ids
+}
+
+func TestTrash(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	kept := addTestBookmark(t, db, "https://a.example", "go")
+	trashed := addTestBookmark(t, db, "https://b.example", "rust")
+
+	if err := db.Delete(ctx, trashed.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := db.Delete(ctx, trashed.ID); err == nil {
+		t.Error("Delete of a bookmark in the trash succeeded; want an error")
+	}
+
+	listed, err := db.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := bookmarkIDs(listed); !reflect.DeepEqual(got, []int64{kept.ID}) {
+		t.Errorf("List = %v, want [%d]", got, kept.ID)
+	}
+	if found, err := db.Search(ctx, "b.example", 10, 0); err != nil || len(found) != 0 {
+		t.Errorf("Search for trashed bookmark = %v, %v; want none", bookmarkIDs(found), err)
+	}
+	if count, err := db.Count(ctx); err != nil || count != 1 {
+		t.Errorf("Count = %d, %v; want 1", count, err)
+	}
+	if tags, err := db.ListAllTags(ctx); err != nil || !reflect.DeepEqual(tags, []string{"go"}) {
+		t.Errorf("ListAllTags = %v, %v; want [go]", tags, err)
+	}
+	if _, err := db.GetByID(ctx, trashed.ID); err == nil {
+		t.Error("GetByID of a bookmark in the trash succeeded; want an error")
+	}
+
+	deleted, err := db.ListDeleted(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListDeleted: %v", err)
+	}
+	if got := bookmarkIDs(deleted); !reflect.DeepEqual(got, []int64{trashed.ID}) {
+		t.Fatalf("ListDeleted = %v, want [%d]", got, trashed.ID)
+	}
+	if deleted[0].DeletedAt == nil {
+		t.Error("ListDeleted returned a bookmark without DeletedAt")
+	}
+
+	// The URL is free again while its bookmark is in the trash, which blocks restoring it
+	again := addTestBookmark(t, db, "https://b.example")
+	if err := db.Restore(ctx, trashed.ID); !errors.Is(err, ErrDuplicateURL) {
+		t.Errorf("Restore with URL bookmarked again = %v, want ErrDuplicateURL", err)
+	}
+	if err := db.Delete(ctx, again.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if err := db.Restore(ctx, trashed.ID); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if err := db.Restore(ctx, kept.ID); err == nil {
+		t.Error("Restore of a bookmark not in the trash succeeded; want an error")
+	}
+	restored, err := db.GetByID(ctx, trashed.ID)
+	if err != nil {
+		t.Fatalf("GetByID after Restore: %v", err)
+	}
+	if restored.DeletedAt != nil || !reflect.DeepEqual(restored.Tags, []string{"rust"}) {
+		t.Errorf("restored bookmark = %+v, want tags [rust] and no DeletedAt", restored)
+	}
+
+	purged, err := db.PurgeDeleted(ctx)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDeleted = %d, %v; want 1", purged, err)
+	}
+	if deleted, err := db.ListDeleted(ctx, 10, 0); err != nil || len(deleted) != 0 {
+		t.Errorf("ListDeleted after PurgeDeleted = %v, %v; want none", bookmarkIDs(deleted), err)
+	}
+	if count, err := db.Count(ctx); err != nil || count != 2 {
+		t.Errorf("Count after PurgeDeleted = %d, %v; want 2", count, err)
+	}
+}
+
+func TestTrashedFilter(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDatabase(t)
+
+	addTestBookmark(t, db, "https://live.example", "go")
+	trashed := addTestBookmark(t, db, "https://trashed.example", "go")
+	if err := db.Delete(ctx, trashed.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter models.BookmarkFilter
+		want   []string
+	}{
+		{"default", models.BookmarkFilter{}, []string{"https://live.example"}},
+		{"include trashed", models.BookmarkFilter{IncludeTrashed: true}, []string{"https://live.example", "https://trashed.example"}},
+		{"only trashed", models.BookmarkFilter{OnlyTrashed: true, Tags: []string{"go"}}, []string{"https://trashed.example"}},
+	}
+	for _, tt := range tests {
+		bookmarks, total, err := db.ListWithCount(ctx, tt.filter, 100, 0)
+		if err != nil {
+			t.Fatalf("%s: ListWithCount: %v", tt.name, err)
+		}
+		var got []string
+		for _, bookmark := range bookmarks {
+			got = append(got, bookmark.URL)
+		}
+		if !reflect.DeepEqual(got, tt.want) || total != len(tt.want) {
+			t.Errorf("%s: list = %v (total %d), want %v", tt.name, got, total, tt.want)
+		}
+		if got := searchURLs(t, db, "example", tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: search = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// internal/fetcher/config.go
+
+package fetcher
+
+import (
+	"context"
+	"time"
+)
+
+// RetryPolicy controls how often a failed fetch is retried and how long to wait
+// between attempts. Delays grow by Multiplier after each attempt, up to MaxDelay.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Multiplier  float64
+}
+
+// FetchConfig holds the tuning knobs for fetching page metadata.
+type FetchConfig struct {
+	Retry         RetryPolicy
+	MaxRedirects  int           // redirects to follow before giving up with "too many redirects"
+	RespectRobots bool          // skip paths disallowed by robots.txt and honor Crawl-delay
+	RobotsTTL     time.Duration // how long a host's robots.txt is cached
+	MaxBodyBytes  int64         // bytes of (decompressed) body to parse; the rest is ignored
+
+	// InsecureSkipVerify accepts any TLS certificate, e.g. self-signed ones on an
+	// intranet. It makes fetches open to interception, so it is off by default.
+	InsecureSkipVerify bool
+
+	// AllowInternalIPs fetches URLs on loopback and private addresses, such as
+	// self-hosted services on a home network. They are refused by default.
+	AllowInternalIPs bool
+
+	// SkipNonHTML sends a HEAD request first and, for resources that aren't HTML such
+	// as PDFs and images, names them after their URL instead of downloading them.
+	SkipNonHTML bool
+}
+
+// DefaultFetchConfig returns the configuration used when none is given: a single
+// attempt and Go's usual limit of 10 redirects, so fetches behave as they did before
+// these were configurable.
+func DefaultFetchConfig() FetchConfig {
+	return FetchConfig{
+		Retry: RetryPolicy{
+			MaxAttempts: 1,
+			BaseDelay:   500 * time.Millisecond,
+			MaxDelay:    10 * time.Second,
+			Multiplier:  2,
+		},
+		MaxRedirects: 10,
+		RobotsTTL:    time.Hour,
+		MaxBodyBytes: 5 << 20,
+	}
+}
+
+// Delay returns how long to wait before the given retry, counting from 1.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := float64(p.BaseDelay)
+	for i := 1; i < retry; i++ {
+		delay *= p.Multiplier
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// Do calls attempt until it reports that no retry is needed or MaxAttempts is
+// reached, sleeping between attempts according to the policy. It gives up without
+// another attempt once ctx is done, including while waiting.
+func (p RetryPolicy) Do(ctx context.Context, attempt func() (retry bool)) {
+	for n := 1; ; n++ {
+		if !attempt() || n >= p.MaxAttempts {
+			return
+		}
+		timer := time.NewTimer(p.Delay(n))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
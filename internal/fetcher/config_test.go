@@ -0,0 +1,43 @@
+package fetcher
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Multiplier: 2}
+
+	attempts := 0
+	policy.Do(context.Background(), func() bool {
+		attempts++
+		return true
+	})
+	if attempts != 3 {
+		t.Errorf("always failing: %d attempts, want 3", attempts)
+	}
+
+	attempts = 0
+	policy.Do(context.Background(), func() bool {
+		attempts++
+		return attempts < 2
+	})
+	if attempts != 2 {
+		t.Errorf("succeeding on the second try: %d attempts, want 2", attempts)
+	}
+
+	// A cancelled context ends the backoff instead of waiting it out
+	policy.BaseDelay = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts = 0
+	start := time.Now()
+	policy.Do(ctx, func() bool {
+		attempts++
+		cancel()
+		return true
+	})
+	if attempts != 1 || time.Since(start) > time.Second {
+		t.Errorf("cancelled: %d attempts in %v, want 1 without waiting", attempts, time.Since(start))
+	}
+}
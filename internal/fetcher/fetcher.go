@@ -3,9 +3,17 @@
 package fetcher
 
 import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net"
 	"net/http"
 	"net/url"
@@ -13,83 +21,380 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
 )
 
 type PageContent struct {
-	Title       string
-	Description string
-	Tags        []string
-	FetchError  string
+	Title              string
+	Description        string
+	Tags               []string
+	Kind               string
+	FinalURL           string // URL the page was served from after following redirects
+	CanonicalURL       string // absolute URL from <link rel="canonical">, if the page sets one
+	FaviconURL         string // absolute URL of the site icon
+	Language           string // from <html lang>, falling back to og:locale, e.g. "en"
+	FeedURL            string // absolute URL of the site's RSS or Atom feed, if the page links one
+	ReadingTimeMinutes int    // estimated minutes to read the main text; 0 if not HTML or empty
+	ImageURL           string // absolute URL of the page's preview image, from og:image or twitter:image
+	FetchError         string
+	HTTPStatus         int  // status of the final response; 0 if none was received
+	Truncated          bool // the body exceeded MaxBodyBytes; metadata comes from its beginning
+	Archived           bool // metadata comes from a Wayback Machine snapshot, not the live page
+	Validators         Validators
+	NotModified        bool // the server answered 304 to a conditional fetch; only HTTPStatus is also set
 }
 
+// Gone reports whether the server said the page no longer exists (404 or 410), as
+// opposed to a failure that may clear up on a later fetch.
+func (c *PageContent) Gone() bool {
+	return c.HTTPStatus == http.StatusNotFound || c.HTTPStatus == http.StatusGone
+}
+
+// Validators are the ETag and Last-Modified values a server sent with a page. Passed
+// back to FetchPageContentIfChanged they let the server answer 304 Not Modified.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsZero reports whether the server sent neither validator.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// Failed reports whether the fetch produced no usable metadata. A truncated body is
+// noted in FetchError but still counts as a success.
+func (c *PageContent) Failed() bool {
+	return c.FetchError != "" && !c.Truncated
+}
+
+// errBlockedByRobots is returned for URLs robots.txt tells goku not to fetch.
+var errBlockedByRobots = errors.New("blocked by robots.txt")
+
+// errTooManyRedirects stops a redirect chain longer than FetchConfig.MaxRedirects.
+var errTooManyRedirects = errors.New("too many redirects")
+
+// Fetcher fetches page metadata using a FetchConfig. It is safe for concurrent use.
+type Fetcher struct {
+	config  FetchConfig
+	domains domainStates
+}
+
+func NewFetcher(config FetchConfig) *Fetcher {
+	return &Fetcher{config: config}
+}
+
+// FetchPageContent fetches pageURL with the default configuration.
 func FetchPageContent(pageURL string) (*PageContent, bool, error) {
+	return FetchPageContentCtx(context.Background(), pageURL)
+}
+
+// FetchPageContentCtx is FetchPageContent with a context that can cancel the request.
+func FetchPageContentCtx(ctx context.Context, pageURL string) (*PageContent, bool, error) {
+	return NewFetcher(DefaultFetchConfig()).FetchPageContentCtx(ctx, pageURL)
+}
+
+// FetchPageContent fetches pageURL without a deadline; see FetchPageContentCtx.
+func (f *Fetcher) FetchPageContent(pageURL string) (*PageContent, bool, error) {
+	return f.FetchPageContentCtx(context.Background(), pageURL)
+}
+
+// FetchPageContentCtx fetches pageURL, retrying network failures and 5xx responses
+// according to the configured RetryPolicy. Cancelling ctx aborts the request in
+// flight and any further retries or backoff. The returned bool reports whether the
+// caller should try another source such as the Wayback Machine.
+func (f *Fetcher) FetchPageContentCtx(ctx context.Context, pageURL string) (*PageContent, bool, error) {
+	return f.FetchPageContentIfChanged(ctx, pageURL, Validators{})
+}
+
+// FetchPageContentIfChanged is FetchPageContentCtx sending If-None-Match and
+// If-Modified-Since from validators. If the server reports the page unchanged, the
+// returned content only has NotModified set.
+func (f *Fetcher) FetchPageContentIfChanged(ctx context.Context, pageURL string, validators Validators) (*PageContent, bool, error) {
+	var content *PageContent
+	var retry bool
+	var err error
+	f.config.Retry.Do(ctx, func() bool {
+		var transient bool
+		content, retry, transient, err = f.fetchPageContentOnce(ctx, pageURL, validators)
+		return transient && ctx.Err() == nil
+	})
+	return content, retry, err
+}
+
+// fetchPageContentOnce makes a single fetch attempt. transient reports a network
+// failure or server error that may succeed if tried again.
+func (f *Fetcher) fetchPageContentOnce(ctx context.Context, pageURL string, validators Validators) (content *PageContent, retry bool, transient bool, err error) {
+	if err := ctx.Err(); err != nil {
+		return &PageContent{FetchError: fmt.Sprintf("Fetch cancelled: %v", err)}, false, false, nil
+	}
+
 	// Validate URL structure
 	parsedURL, err := url.ParseRequestURI(pageURL)
 	if err != nil {
-		return &PageContent{FetchError: fmt.Sprintf("Invalid URL format: %v", err)}, false, nil
+		return &PageContent{FetchError: fmt.Sprintf("Invalid URL format: %v", err)}, false, false, nil
 	}
 
 	// Check if the URL has a valid host
 	if parsedURL.Host == "" {
-		return &PageContent{FetchError: "URL must have a valid host"}, false, nil
+		return &PageContent{FetchError: "URL must have a valid host"}, false, false, nil
+	}
+
+	if !f.config.AllowInternalIPs && ValidateIfInternalIP(pageURL) {
+		return &PageContent{FetchError: "Internal IP addresses are not supported (use --allow-internal to fetch them)"}, false, false, nil
 	}
 
-	if ValidateIfInternalIP(pageURL) {
-		return &PageContent{FetchError: "Internal IP addresses are not supported"}, false, nil
+	if f.config.RespectRobots {
+		if !f.robotsAllowed(parsedURL) {
+			return &PageContent{FetchError: errBlockedByRobots.Error()}, false, false, nil
+		}
+		f.waitForDomain(parsedURL.Host)
 	}
 
 	alive, err := IsWebsiteAccessible(pageURL)
 	if err != nil {
-		return &PageContent{FetchError: fmt.Sprintf("Failed to check website accessibility: %v", err)}, true, nil
+		return &PageContent{FetchError: fmt.Sprintf("Failed to check website accessibility: %v", err)}, true, true, nil
 	}
 	if !alive {
-		return &PageContent{FetchError: "Website is not accessible"}, false, nil
+		return &PageContent{FetchError: "Website is not accessible"}, true, true, nil
 	}
 
-	client := &http.Client{
-		Timeout: 250 * time.Millisecond,
+	client := f.newClient(250 * time.Millisecond)
+
+	if f.config.SkipNonHTML {
+		if content := preflightNonHTML(ctx, client, pageURL); content != nil {
+			return content, false, false, nil
+		}
 	}
 
-	resp, err := client.Get(pageURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
 	if err != nil {
-		return &PageContent{FetchError: fmt.Sprintf("Failed to fetch URL: %v", err)}, false, nil
+		return &PageContent{FetchError: fmt.Sprintf("Failed to create request: %v", err)}, false, false, nil
+	}
+	// Asking explicitly turns off Go's transparent gzip handling, so decodeBody
+	// also covers servers that compress without being asked
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	setConditionalHeaders(req, validators)
+
+	resp, err := client.Do(req)
+	if errors.Is(err, errTooManyRedirects) {
+		return &PageContent{FetchError: errTooManyRedirects.Error()}, false, false, nil
+	}
+	if err != nil {
+		return &PageContent{FetchError: fmt.Sprintf("Failed to fetch URL: %v", err)}, true, true, nil
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && !validators.IsZero() {
+		return &PageContent{NotModified: true, HTTPStatus: resp.StatusCode}, false, false, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return &PageContent{FetchError: fmt.Sprintf("HTTP code: %d, cannot get metadata", resp.StatusCode)}, false, nil
+		content := &PageContent{
+			FetchError: fmt.Sprintf("HTTP code: %d, cannot get metadata", resp.StatusCode),
+			HTTPStatus: resp.StatusCode,
+		}
+		return content, content.Gone(), isServerError(resp.StatusCode), nil
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	return f.parseResponse(resp, pageURL, parsedURL.Host), false, false, nil
+}
+
+// isServerError reports whether status is a 5xx, which may succeed if retried.
+func isServerError(status int) bool {
+	return status >= 500 && status <= 599
+}
+
+// parseResponse extracts page metadata from a successful response, decompressing,
+// transcoding and reading at most MaxBodyBytes of its body.
+func (f *Fetcher) parseResponse(resp *http.Response, pageURL, host string) *PageContent {
+	kind := InferKind(pageURL, resp.Header.Get("Content-Type"))
+
+	body, err := decodeBody(resp)
+	if err != nil {
+		return &PageContent{Kind: kind, HTTPStatus: resp.StatusCode, FetchError: fmt.Sprintf("Failed to decompress response: %v", err)}
+	}
+	defer body.Close()
+
+	limited := &io.LimitedReader{R: body, N: f.config.MaxBodyBytes}
+	var parsed io.Reader = limited
+	if f.config.MaxBodyBytes <= 0 {
+		parsed = body
+	}
+
+	doc, err := goquery.NewDocumentFromReader(utf8Body(parsed, resp.Header.Get("Content-Type")))
 	if err != nil {
-		return &PageContent{FetchError: fmt.Sprintf("Failed to parse HTML: %v", err)}, false, nil
+		return &PageContent{Kind: kind, HTTPStatus: resp.StatusCode, FetchError: fmt.Sprintf("Failed to parse HTML: %v", err)}
 	}
 
+	base := documentBaseURL(doc, resp.Request.URL)
 	content := &PageContent{
-		Title:       extractTitle(doc),
-		Description: extractDescription(doc, parsedURL.Host),
-		Tags:        extractTags(doc),
+		Title:              extractTitle(doc),
+		Description:        extractDescription(doc, host),
+		Tags:               extractTags(doc),
+		Kind:               kind,
+		FinalURL:           resp.Request.URL.String(),
+		CanonicalURL:       extractCanonicalURL(doc, base),
+		FaviconURL:         extractFaviconURL(doc, base),
+		ImageURL:           extractImageURL(doc, base),
+		Language:           extractLanguage(doc),
+		FeedURL:            extractFeedURL(doc, base),
+		ReadingTimeMinutes: readingTime(doc, resp.Header.Get("Content-Type")),
+		HTTPStatus:         resp.StatusCode,
+		Validators:         responseValidators(resp),
+	}
+	if f.config.MaxBodyBytes > 0 && limited.N == 0 && bodyHasMore(body) {
+		content.Truncated = true
+		content.FetchError = fmt.Sprintf("response body truncated at %d bytes", f.config.MaxBodyBytes)
+		log.Printf("Warning: %s: %s", content.FinalURL, content.FetchError)
+	}
+
+	return content
+}
+
+// setConditionalHeaders makes req conditional on the page having changed since it
+// was fetched with validators.
+func setConditionalHeaders(req *http.Request, validators Validators) {
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+}
+
+// responseValidators returns the validators resp carries for later conditional fetches.
+func responseValidators(resp *http.Response) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// decodeBody returns resp.Body decompressed according to its Content-Encoding.
+// Closing the returned reader does not close resp.Body.
+func decodeBody(resp *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(resp.Body)
+	case "deflate":
+		// HTTP deflate is meant to be zlib-wrapped, but some servers send raw deflate
+		body := bufio.NewReader(resp.Body)
+		header, err := body.Peek(2)
+		if err == nil && isZlibHeader(header) {
+			return zlib.NewReader(body)
+		}
+		return flate.NewReader(body), nil
+	default:
+		return io.NopCloser(resp.Body), nil
+	}
+}
+
+// bodyHasMore reports whether body has at least one more byte to read.
+func bodyHasMore(body io.Reader) bool {
+	var probe [1]byte
+	n, _ := io.ReadFull(body, probe[:])
+	return n == 1
+}
+
+// isZlibHeader reports whether b starts a zlib stream using deflate compression.
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// utf8Body transcodes an HTML body to UTF-8, detecting its encoding from a byte order
+// mark, the Content-Type header or a <meta charset> in the first 1024 bytes.
+func utf8Body(body io.Reader, contentType string) io.Reader {
+	decoded, err := charset.NewReader(body, contentType)
+	if err != nil {
+		// Only a failed read ends up here; let the HTML parser report it
+		log.Printf("Warning: cannot detect page encoding: %v", err)
+		return body
+	}
+	return decoded
+}
+
+// newClient returns an HTTP client that follows at most MaxRedirects redirects and,
+// with InsecureSkipVerify, accepts any TLS certificate.
+func (f *Fetcher) newClient(timeout time.Duration) *http.Client {
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > f.config.MaxRedirects {
+				return errTooManyRedirects
+			}
+			return nil
+		},
+	}
+	if f.config.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		client.Transport = transport
+	}
+	return client
+}
+
+// ContentType returns the media type a URL is served with, e.g. "text/html", using a
+// HEAD request and falling back to GET for servers that don't support HEAD.
+func (f *Fetcher) ContentType(pageURL string) (string, error) {
+	client := f.newClient(10 * time.Second)
+
+	if f.config.RespectRobots {
+		parsedURL, err := url.Parse(pageURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL: %w", err)
+		}
+		if !f.robotsAllowed(parsedURL) {
+			return "", errBlockedByRobots
+		}
+		f.waitForDomain(parsedURL.Host)
 	}
 
-	return content, false, nil
+	var contentType string
+	var err error
+	f.config.Retry.Do(context.Background(), func() bool {
+		contentType, err = fetchContentTypeOnce(client, pageURL)
+		return err != nil
+	})
+	return contentType, err
 }
 
+func fetchContentTypeOnce(client *http.Client, pageURL string) (string, error) {
+	resp, err := client.Head(pageURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = client.Get(pageURL)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("HTTP code: %d", resp.StatusCode)
+	}
+
+	mediaType := strings.Split(resp.Header.Get("Content-Type"), ";")[0]
+	return strings.ToLower(strings.TrimSpace(mediaType)), nil
+}
+
+// extractTitle returns the page's <title>, falling back to a JSON-LD headline.
 func extractTitle(doc *goquery.Document) string {
-	title := doc.Find("title").First().Text()
-	return strings.TrimSpace(title)
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if title != "" {
+		return title
+	}
+	headline, _ := extractJSONLD(doc)
+	return headline
 }
 
 func extractDescription(doc *goquery.Document, host string) string {
-	// Try standard meta description
-	description, _ := doc.Find("meta[name='description']").Attr("content")
+	// Try standard meta description, then Open Graph, then JSON-LD structured data
+	description := findMetaContent(doc, "description", "og:description")
 	if description != "" {
-		return strings.TrimSpace(description)
+		return description
 	}
-
-	// Try Open Graph description
-	description, _ = doc.Find("meta[property='og:description']").Attr("content")
-	if description != "" {
-		return strings.TrimSpace(description)
+	if _, description = extractJSONLD(doc); description != "" {
+		return description
 	}
 
 	// Special handling for known sites
@@ -104,11 +409,181 @@ func extractDescription(doc *goquery.Document, host string) string {
 	return strings.TrimSpace(description)
 }
 
+// findMetaContent returns the first non-empty content of a <meta> tag whose name or
+// property is one of keys, trying the keys in order.
+func findMetaContent(doc *goquery.Document, keys ...string) string {
+	for _, key := range keys {
+		var content string
+		doc.Find("meta").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			name, _ := s.Attr("name")
+			property, _ := s.Attr("property")
+			if !strings.EqualFold(name, key) && !strings.EqualFold(property, key) {
+				return true
+			}
+			value, _ := s.Attr("content")
+			content = strings.TrimSpace(value)
+			return content == ""
+		})
+		if content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+// extractImageURL returns the page's preview image, preferring Open Graph over Twitter cards.
+func extractImageURL(doc *goquery.Document, base *url.URL) string {
+	return resolveURL(base, findMetaContent(doc, "og:image", "og:image:url", "twitter:image", "twitter:image:src"))
+}
+
 func extractHackerNewsDescription(doc *goquery.Document) string {
 	title := doc.Find("td.title").First().Text()
 	return strings.TrimSpace(title)
 }
 
+// documentBaseURL returns the URL relative links in doc resolve against: the
+// <base href> if the page sets one, otherwise the URL the page was served from.
+func documentBaseURL(doc *goquery.Document, pageURL *url.URL) *url.URL {
+	href, _ := doc.Find("base[href]").First().Attr("href")
+	if href == "" {
+		return pageURL
+	}
+	base, err := pageURL.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return pageURL
+	}
+	return base
+}
+
+// resolveURL makes ref absolute against base, returning "" if it can't be parsed.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
+
+// extractCanonicalURL returns the page's <link rel="canonical"> made absolute, or "".
+func extractCanonicalURL(doc *goquery.Document, base *url.URL) string {
+	var canonical string
+	doc.Find("link[rel][href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		rel, _ := s.Attr("rel")
+		if !strings.EqualFold(strings.TrimSpace(rel), "canonical") {
+			return true
+		}
+		href, _ := s.Attr("href")
+		canonical = resolveURL(base, href)
+		return canonical == ""
+	})
+	return canonical
+}
+
+// wordsPerMinute is the reading speed readingTime assumes.
+const wordsPerMinute = 200
+
+// readingTime estimates how many minutes the page's main text takes to read, rounded
+// up. Only HTML is measured; the main text is the <article> or <main> element when the
+// page has one, otherwise the body without navigation, headers, footers and scripts.
+func readingTime(doc *goquery.Document, contentType string) int {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return 0
+	}
+
+	main := doc.Find("article").First()
+	if main.Length() == 0 {
+		main = doc.Find("main").First()
+	}
+	if main.Length() == 0 {
+		main = doc.Find("body").First()
+	}
+	main = main.Clone()
+	main.Find("script, style, noscript, template, nav, header, footer, aside").Remove()
+
+	words := len(strings.Fields(main.Text()))
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// extractLanguage returns the page's language from the lang attribute of <html>,
+// falling back to og:locale. Only the lowercased primary subtag is kept, so "en-US"
+// and og:locale's "en_GB" both become "en".
+func extractLanguage(doc *goquery.Document) string {
+	lang, _ := doc.Find("html").First().Attr("lang")
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		lang = findMetaContent(doc, "og:locale")
+	}
+	primary, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	return strings.ToLower(strings.TrimSpace(primary))
+}
+
+// faviconRels lists the <link rel> values that name a site icon, in order of preference.
+var faviconRels = []string{"icon", "shortcut icon", "apple-touch-icon"}
+
+// extractFaviconURL returns the page's icon from its <link> tags, falling back to
+// /favicon.ico on the page's host.
+func extractFaviconURL(doc *goquery.Document, base *url.URL) string {
+	icons := make(map[string]string)
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		rel = strings.ToLower(strings.Join(strings.Fields(rel), " "))
+		href, _ := s.Attr("href")
+		if _, seen := icons[rel]; !seen && strings.TrimSpace(href) != "" {
+			icons[rel] = href
+		}
+	})
+
+	for _, rel := range faviconRels {
+		if href, ok := icons[rel]; ok {
+			if favicon := resolveURL(base, href); favicon != "" {
+				return favicon
+			}
+		}
+	}
+
+	return (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: "/favicon.ico"}).String()
+}
+
+// feedTypes lists the <link rel="alternate"> types that announce a feed, in order of
+// preference.
+var feedTypes = []string{"application/rss+xml", "application/atom+xml"}
+
+// extractFeedURL returns the first RSS feed the page links to, else the first Atom
+// feed, or "" if it links neither.
+func extractFeedURL(doc *goquery.Document, base *url.URL) string {
+	feeds := make(map[string]string)
+	doc.Find("link[rel][href][type]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		alternate := false
+		for _, token := range strings.Fields(rel) {
+			alternate = alternate || strings.EqualFold(token, "alternate")
+		}
+		if !alternate {
+			return
+		}
+		feedType, _ := s.Attr("type")
+		feedType = strings.ToLower(strings.TrimSpace(feedType))
+		href, _ := s.Attr("href")
+		if _, seen := feeds[feedType]; !seen && strings.TrimSpace(href) != "" {
+			feeds[feedType] = href
+		}
+	})
+
+	for _, feedType := range feedTypes {
+		if href, ok := feeds[feedType]; ok {
+			if feed := resolveURL(base, href); feed != "" {
+				return feed
+			}
+		}
+	}
+	return ""
+}
+
 func extractTags(doc *goquery.Document) []string {
 	var tags []string
 
@@ -196,6 +671,9 @@ func IsWebsiteAccessible(url string) (bool, error) {
 	return accessible, nil
 }
 
+// waybackAvailabilityURL is the Wayback Machine API that finds a URL's closest snapshot.
+var waybackAvailabilityURL = "https://archive.org/wayback/available"
+
 type WaybackResponse struct {
 	ArchivedSnapshots struct {
 		Closest struct {
@@ -206,15 +684,23 @@ type WaybackResponse struct {
 	} `json:"archived_snapshots"`
 }
 
+// FetchMetadataFromWaybackMachine is FetchMetadataFromWaybackMachineCtx without a context.
 func FetchMetadataFromWaybackMachine(urlStr string) (*PageContent, error) {
+	return FetchMetadataFromWaybackMachineCtx(context.Background(), urlStr)
+}
+
+// FetchMetadataFromWaybackMachineCtx reads metadata from the closest Wayback Machine
+// snapshot of urlStr. Failures are reported in FetchError; the content is marked
+// Archived on success.
+func FetchMetadataFromWaybackMachineCtx(ctx context.Context, urlStr string) (*PageContent, error) {
 	// Construct the Wayback Machine API URL
-	apiURL := fmt.Sprintf("https://archive.org/wayback/available?url=%s", url.QueryEscape(urlStr))
+	apiURL := fmt.Sprintf("%s?url=%s", waybackAvailabilityURL, url.QueryEscape(urlStr))
 
 	// Create an HTTP client with a timeout
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	// Make the request to the Wayback Machine API
-	resp, err := client.Get(apiURL)
+	resp, err := getWithContext(ctx, client, apiURL)
 	if err != nil {
 		return &PageContent{FetchError: fmt.Sprintf("failed to fetch Wayback Machine API: %v", err)}, nil
 	}
@@ -238,14 +724,17 @@ func FetchMetadataFromWaybackMachine(urlStr string) (*PageContent, error) {
 	}
 
 	// Fetch the archived page
-	archivedResp, err := client.Get(waybackResp.ArchivedSnapshots.Closest.URL)
+	archivedResp, err := getWithContext(ctx, client, waybackResp.ArchivedSnapshots.Closest.URL)
 	if err != nil {
 		return &PageContent{FetchError: fmt.Sprintf("failed to fetch archived page: %v", err)}, nil
 	}
 	defer archivedResp.Body.Close()
+	if archivedResp.StatusCode != http.StatusOK {
+		return &PageContent{FetchError: fmt.Sprintf("archived page HTTP code: %d", archivedResp.StatusCode)}, nil
+	}
 
 	// Parse the HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(utf8Body(archivedResp.Body, archivedResp.Header.Get("Content-Type")))
 	if err != nil {
 		return &PageContent{FetchError: fmt.Sprintf("Failed to parse HTML: %v", err)}, nil
 	}
@@ -255,7 +744,17 @@ func FetchMetadataFromWaybackMachine(urlStr string) (*PageContent, error) {
 		Title:       extractTitle(doc),
 		Description: extractDescription(doc, urlStr),
 		Tags:        extractTags(doc),
+		Archived:    true,
 	}
 
 	return content, nil
 }
+
+// getWithContext is client.Get with a request that ctx can cancel.
+func getWithContext(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
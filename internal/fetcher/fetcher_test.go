@@ -0,0 +1,512 @@
+package fetcher
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// newRedirectServer serves /hops/N, which redirects N times before answering with HTML.
+func newRedirectServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hops, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hops/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if hops > 0 {
+			http.Redirect(w, r, fmt.Sprintf("/hops/%d", hops-1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><title>done</title></html>")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMaxRedirects(t *testing.T) {
+	server := newRedirectServer(t)
+
+	config := DefaultFetchConfig()
+	config.MaxRedirects = 3
+	f := NewFetcher(config)
+
+	contentType, err := f.ContentType(server.URL + "/hops/3")
+	if err != nil || contentType != "text/html" {
+		t.Errorf("3 redirects with a limit of 3: got %q, %v; want text/html", contentType, err)
+	}
+
+	_, err = f.ContentType(server.URL + "/hops/4")
+	if !errors.Is(err, errTooManyRedirects) {
+		t.Errorf("4 redirects with a limit of 3: got error %v, want %v", err, errTooManyRedirects)
+	}
+}
+
+func TestExtractFaviconURL(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		html string
+		want string
+	}{
+		{"relative icon", "https://example.com/a/b", `<link rel="icon" href="/static/icon.png">`, "https://example.com/static/icon.png"},
+		{"path-relative icon", "https://example.com/a/b", `<link rel="shortcut icon" href="fav.ico">`, "https://example.com/a/fav.ico"},
+		{"icon preferred over apple-touch-icon", "https://example.com", `<link rel="apple-touch-icon" href="/touch.png"><link rel="icon" href="/icon.png">`, "https://example.com/icon.png"},
+		{"apple-touch-icon only", "https://example.com", `<link rel="apple-touch-icon" href="https://cdn.example.net/touch.png">`, "https://cdn.example.net/touch.png"},
+		{"base href", "https://example.com/a/b", `<base href="https://static.example.com/root/"><link rel="icon" href="icon.png">`, "https://static.example.com/root/icon.png"},
+		{"fallback", "http://example.com:8080/a?b=c", `<link rel="stylesheet" href="/style.css">`, "http://example.com:8080/favicon.ico"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+			if err != nil {
+				t.Fatalf("parsing HTML: %v", err)
+			}
+			page, _ := url.Parse(tt.page)
+			if got := extractFaviconURL(doc, documentBaseURL(doc, page)); got != tt.want {
+				t.Errorf("extractFaviconURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractFeedURL(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"rss", `<link rel="alternate" type="application/rss+xml" href="/feed.xml">`, "https://example.com/feed.xml"},
+		{"atom", `<link rel="alternate" type="application/atom+xml" href="atom.xml">`, "https://example.com/posts/atom.xml"},
+		{"rss preferred over earlier atom", `<link rel="alternate" type="application/atom+xml" href="/atom"><link rel="alternate" type="application/rss+xml" href="/rss"><link rel="alternate" type="application/rss+xml" href="/rss2">`, "https://example.com/rss"},
+		{"rel token list", `<link rel="Alternate home" type="Application/RSS+XML" href="https://feeds.example.net/x">`, "https://feeds.example.net/x"},
+		{"not alternate", `<link rel="stylesheet" type="application/rss+xml" href="/feed.xml">`, ""},
+		{"other alternate", `<link rel="alternate" hreflang="de" type="text/html" href="/de/">`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+			if err != nil {
+				t.Fatalf("parsing HTML: %v", err)
+			}
+			page, _ := url.Parse("https://example.com/posts/1")
+			if got := extractFeedURL(doc, documentBaseURL(doc, page)); got != tt.want {
+				t.Errorf("extractFeedURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractImageURL(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"og:image", `<meta property="og:image" content="/img/cover.png">`, "https://example.com/img/cover.png"},
+		{"first non-empty og:image", `<meta property="og:image" content=" "><meta property="og:image" content="https://cdn.example.net/a.jpg"><meta property="og:image" content="/b.jpg">`, "https://cdn.example.net/a.jpg"},
+		{"og:image preferred over twitter:image", `<meta name="twitter:image" content="/twitter.png"><meta property="og:image" content="/og.png">`, "https://example.com/og.png"},
+		{"twitter:image fallback", `<meta name="twitter:image" content="card.png">`, "https://example.com/posts/card.png"},
+		{"none", `<meta name="description" content="x">`, ""},
+	}
+
+	page, _ := url.Parse("https://example.com/posts/1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+			if err != nil {
+				t.Fatalf("parsing HTML: %v", err)
+			}
+			if got := extractImageURL(doc, page); got != tt.want {
+				t.Errorf("extractImageURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"absolute", `<link rel="canonical" href="https://example.com/article">`, "https://example.com/article"},
+		{"relative", `<link rel="icon" href="/icon.png"><link rel="Canonical" href="/posts/1">`, "https://example.com/posts/1"},
+		{"none", `<link rel="icon" href="/icon.png">`, ""},
+	}
+
+	page, _ := url.Parse("https://example.com/posts/1?utm_source=feed")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+			if err != nil {
+				t.Fatalf("parsing HTML: %v", err)
+			}
+			if got := extractCanonicalURL(doc, page); got != tt.want {
+				t.Errorf("extractCanonicalURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUTF8Body(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"utf-8", "text/html; charset=utf-8", "<title>café</title>", "café"},
+		{"latin-1 from header", "text/html; charset=ISO-8859-1", "<title>caf\xe9</title>", "café"},
+		{"shift_jis from meta", "text/html", `<meta charset="Shift_JIS"><title>` + "\x93\xfa\x96\x7b" + `</title>`, "日本"},
+		{"euc-jp from http-equiv", "text/html", `<meta http-equiv="Content-Type" content="text/html; charset=EUC-JP"><title>` + "\xc6\xfc\xcb\xdc" + `</title>`, "日本"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(utf8Body(strings.NewReader("<html><head>"+tt.body+"</head></html>"), tt.contentType))
+			if err != nil {
+				t.Fatalf("parsing HTML: %v", err)
+			}
+			if got := extractTitle(doc); got != tt.want {
+				t.Errorf("title = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	const page = "<html><title>compressed</title></html>"
+	compress := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip":        func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"zlib":        func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw deflate": func(w io.Writer) io.WriteCloser { fw, _ := flate.NewWriter(w, flate.DefaultCompression); return fw },
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		compress string
+	}{
+		{"identity", "", ""},
+		{"gzip", "gzip", "gzip"},
+		{"deflate", "deflate", "zlib"},
+		{"raw deflate", "deflate", "raw deflate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if tt.compress == "" {
+				buf.WriteString(page)
+			} else {
+				w := compress[tt.compress](&buf)
+				io.WriteString(w, page)
+				w.Close()
+			}
+
+			resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(&buf)}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+			body, err := decodeBody(resp)
+			if err != nil {
+				t.Fatalf("decodeBody: %v", err)
+			}
+			defer body.Close()
+
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("reading body: %v", err)
+			}
+			if string(got) != page {
+				t.Errorf("body = %q, want %q", got, page)
+			}
+		})
+	}
+}
+
+func TestFetchPageContentCtxCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	content, _, err := NewFetcher(DefaultFetchConfig()).FetchPageContentCtx(ctx, "https://example.com")
+	if err != nil {
+		t.Fatalf("FetchPageContentCtx: %v", err)
+	}
+	if !strings.Contains(content.FetchError, "cancel") {
+		t.Errorf("FetchError = %q, want a cancellation", content.FetchError)
+	}
+}
+
+func TestParseResponseMaxBodyBytes(t *testing.T) {
+	page := "<html><head><title>big</title></head><body>" + strings.Repeat("x", 1000) + "</body></html>"
+	newResponse := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/big", nil)
+		return &http.Response{
+			Header:  http.Header{"Content-Type": []string{"text/html"}},
+			Body:    io.NopCloser(strings.NewReader(page)),
+			Request: req,
+		}
+	}
+
+	config := DefaultFetchConfig()
+	config.MaxBodyBytes = 100
+	content := NewFetcher(config).parseResponse(newResponse(), "https://example.com/big", "example.com")
+	if !content.Truncated || !strings.Contains(content.FetchError, "truncated at 100 bytes") {
+		t.Errorf("Truncated = %t, FetchError = %q, want a truncation note", content.Truncated, content.FetchError)
+	}
+	if content.Title != "big" || content.Failed() {
+		t.Errorf("Title = %q, Failed = %t, want metadata from the start of the page", content.Title, content.Failed())
+	}
+
+	config.MaxBodyBytes = int64(len(page))
+	content = NewFetcher(config).parseResponse(newResponse(), "https://example.com/big", "example.com")
+	if content.Truncated || content.FetchError != "" {
+		t.Errorf("page exactly at the limit: Truncated = %t, FetchError = %q", content.Truncated, content.FetchError)
+	}
+}
+
+func TestConditionalHeaders(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "page.html", modified, strings.NewReader("<title>page</title>"))
+	}))
+	defer server.Close()
+
+	get := func(validators Validators) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		setConditionalHeaders(req, validators)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	first := get(Validators{})
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("unconditional GET status = %d, want 200", first.StatusCode)
+	}
+	validators := responseValidators(first)
+	want := Validators{ETag: `"v1"`, LastModified: modified.Format(http.TimeFormat)}
+	if validators != want {
+		t.Fatalf("responseValidators = %+v, want %+v", validators, want)
+	}
+
+	tests := []struct {
+		name       string
+		validators Validators
+		want       int
+	}{
+		{"matching etag", Validators{ETag: `"v1"`}, http.StatusNotModified},
+		{"stale etag", Validators{ETag: `"v0"`}, http.StatusOK},
+		{"last-modified only", Validators{LastModified: want.LastModified}, http.StatusNotModified},
+		{"both", validators, http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		if got := get(tt.validators).StatusCode; got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPageContentGone(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{0, false},
+		{http.StatusOK, false},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, true},
+		{http.StatusGone, true},
+		{http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		if got := (&PageContent{HTTPStatus: tt.status}).Gone(); got != tt.want {
+			t.Errorf("Gone() with status %d = %t, want %t", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestParseResponseHTTPStatus(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/html"}},
+		Body:       io.NopCloser(strings.NewReader("<title>ok</title>")),
+		Request:    req,
+	}
+	content := NewFetcher(DefaultFetchConfig()).parseResponse(resp, "https://example.com/", "example.com")
+	if content.HTTPStatus != http.StatusOK {
+		t.Errorf("HTTPStatus = %d, want %d", content.HTTPStatus, http.StatusOK)
+	}
+}
+
+func TestIsServerError(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusNotFound:            false,
+		http.StatusTooManyRequests:     false,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusServiceUnavailable:  true,
+	} {
+		if got := isServerError(status); got != want {
+			t.Errorf("isServerError(%d) = %t, want %t", status, got, want)
+		}
+	}
+}
+
+func TestExtractLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"html lang", `<html lang="en-US"><head><meta property="og:locale" content="fr_FR"></head></html>`, "en"},
+		{"og:locale fallback", `<html><head><meta property="og:locale" content="pt_BR"></head></html>`, "pt"},
+		{"script subtag", `<html lang="zh-Hant-TW"></html>`, "zh"},
+		{"blank lang falls back", `<html lang=" "><head><meta property="og:locale" content="de"></head></html>`, "de"},
+		{"none", `<html><head><title>x</title></head></html>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := extractLanguage(doc); got != tt.want {
+				t.Errorf("extractLanguage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchMetadataFromWaybackMachine(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/available":
+			if r.URL.Query().Get("url") != "https://dead.example/post" {
+				t.Errorf("availability lookup for %q", r.URL.Query().Get("url"))
+			}
+			fmt.Fprintf(w, `{"archived_snapshots":{"closest":{"available":true,"url":%q,"timestamp":"20200101000000"}}}`, server.URL+"/snapshot")
+		case "/snapshot":
+			fmt.Fprint(w, `<html><head><title>Archived post</title><meta name="description" content="From 2020"></head></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	saved := waybackAvailabilityURL
+	waybackAvailabilityURL = server.URL + "/available"
+	defer func() { waybackAvailabilityURL = saved }()
+
+	content, err := FetchMetadataFromWaybackMachineCtx(context.Background(), "https://dead.example/post")
+	if err != nil {
+		t.Fatalf("FetchMetadataFromWaybackMachineCtx: %v", err)
+	}
+	if content.Failed() || !content.Archived {
+		t.Fatalf("FetchError = %q, Archived = %t, want archived metadata", content.FetchError, content.Archived)
+	}
+	if content.Title != "Archived post" || content.Description != "From 2020" {
+		t.Errorf("got title %q, description %q from the snapshot", content.Title, content.Description)
+	}
+}
+
+func TestInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<title>intranet</title>")
+	}))
+	defer server.Close()
+
+	config := DefaultFetchConfig()
+	if _, err := NewFetcher(config).newClient(time.Second).Get(server.URL); err == nil {
+		t.Error("self-signed certificate accepted by default")
+	}
+
+	config.InsecureSkipVerify = true
+	resp, err := NewFetcher(config).newClient(time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("with InsecureSkipVerify: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestReadingTime(t *testing.T) {
+	words := func(n int) string { return strings.TrimSpace(strings.Repeat("word ", n)) }
+	tests := []struct {
+		name        string
+		html        string
+		contentType string
+		want        int
+	}{
+		{"rounds up", "<body><p>" + words(201) + "</p></body>", "text/html; charset=utf-8", 2},
+		{"article only", "<body><nav>" + words(1000) + "</nav><article>" + words(150) + "</article></body>", "text/html", 1},
+		{"skips scripts and chrome", "<body><header>" + words(500) + "</header><script>" + words(500) + "</script><p>" + words(400) + "</p></body>", "text/html", 2},
+		{"missing content type is parsed as HTML", "<body>" + words(10) + "</body>", "", 1},
+		{"not HTML", "<body>" + words(1000) + "</body>", "application/pdf", 0},
+		{"empty page", "<body></body>", "text/html", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html>" + tt.html + "</html>"))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := readingTime(doc, tt.contentType); got != tt.want {
+				t.Errorf("readingTime = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowInternalIPs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><title>Grafana</title></html>")
+	}))
+	defer server.Close()
+
+	content, _, err := NewFetcher(DefaultFetchConfig()).FetchPageContent(server.URL)
+	if err != nil {
+		t.Fatalf("FetchPageContent: %v", err)
+	}
+	if !strings.HasPrefix(content.FetchError, "Internal IP addresses are not supported") {
+		t.Errorf("default config fetched a loopback URL: %+v", content)
+	}
+
+	config := DefaultFetchConfig()
+	config.AllowInternalIPs = true
+	content, _, err = NewFetcher(config).FetchPageContent(server.URL)
+	if err != nil {
+		t.Fatalf("FetchPageContent: %v", err)
+	}
+	if content.FetchError != "" || content.Title != "Grafana" {
+		t.Errorf("with AllowInternalIPs got title %q, error %q", content.Title, content.FetchError)
+	}
+}
@@ -0,0 +1,71 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// jsonLDObjects returns every object in the page's <script type="application/ld+json">
+// blocks, in document order. Top-level arrays and @graph wrappers are flattened;
+// blocks that aren't valid JSON are skipped.
+func jsonLDObjects(doc *goquery.Document) []map[string]interface{} {
+	var objects []map[string]interface{}
+	doc.Find("script").Each(func(_ int, s *goquery.Selection) {
+		scriptType, _ := s.Attr("type")
+		if !strings.EqualFold(strings.TrimSpace(scriptType), "application/ld+json") {
+			return
+		}
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			log.Printf("Warning: skipping invalid JSON-LD block: %v", err)
+			return
+		}
+		objects = appendJSONLDObjects(objects, data)
+	})
+	return objects
+}
+
+// appendJSONLDObjects appends the objects in data to objects, descending into arrays
+// and @graph.
+func appendJSONLDObjects(objects []map[string]interface{}, data interface{}) []map[string]interface{} {
+	switch value := data.(type) {
+	case []interface{}:
+		for _, item := range value {
+			objects = appendJSONLDObjects(objects, item)
+		}
+	case map[string]interface{}:
+		if graph, ok := value["@graph"]; ok {
+			return appendJSONLDObjects(objects, graph)
+		}
+		objects = append(objects, value)
+	}
+	return objects
+}
+
+// extractJSONLD returns the headline and description of the first JSON-LD object with
+// a headline, such as an Article or Recipe. Without one, the description comes from
+// the first object that has any.
+func extractJSONLD(doc *goquery.Document) (headline, description string) {
+	objects := jsonLDObjects(doc)
+	for _, object := range objects {
+		if headline = jsonLDString(object, "headline"); headline != "" {
+			return headline, jsonLDString(object, "description")
+		}
+	}
+	for _, object := range objects {
+		if description = jsonLDString(object, "description"); description != "" {
+			return "", description
+		}
+	}
+	return "", ""
+}
+
+// jsonLDString returns the trimmed string value of key in object, or "" if it is
+// missing or not a string.
+func jsonLDString(object map[string]interface{}, key string) string {
+	value, _ := object[key].(string)
+	return strings.TrimSpace(value)
+}
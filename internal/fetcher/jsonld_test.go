@@ -0,0 +1,73 @@
+package fetcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractJSONLD(t *testing.T) {
+	tests := []struct {
+		name            string
+		scripts         string
+		wantHeadline    string
+		wantDescription string
+	}{
+		{
+			"single object",
+			`{"@type": "NewsArticle", "headline": " Rates rise ", "description": "The bank moved."}`,
+			"Rates rise", "The bank moved.",
+		},
+		{
+			"top-level array",
+			`[{"@type": "Organization", "name": "Paper"}, {"@type": "Recipe", "headline": "Soup", "description": "Warm."}]`,
+			"Soup", "Warm.",
+		},
+		{
+			"graph wrapper",
+			`{"@context": "https://schema.org", "@graph": [{"@type": "WebSite", "description": "A site"}, {"@type": "Article", "headline": "Post"}]}`,
+			"Post", "",
+		},
+		{
+			"description without headline",
+			`{"@graph": [{"@type": "WebPage", "description": "About us"}]}`,
+			"", "About us",
+		},
+		{
+			"invalid block is skipped",
+			`{not json</script><script type="application/ld+json">{"headline": "Second"}`,
+			"Second", "",
+		},
+		{"none", `{"@type": "Thing"}`, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := `<html><head><script type="application/ld+json">` + tt.scripts + `</script></head></html>`
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			headline, description := extractJSONLD(doc)
+			if headline != tt.wantHeadline || description != tt.wantDescription {
+				t.Errorf("extractJSONLD = (%q, %q), want (%q, %q)", headline, description, tt.wantHeadline, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestJSONLDFallbacks(t *testing.T) {
+	html := `<html><head><script type="application/ld+json">{"headline": "From JSON-LD", "description": "Structured"}</script></head>
+		<body><p>First paragraph</p></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := extractTitle(doc); got != "From JSON-LD" {
+		t.Errorf("extractTitle without <title> = %q, want the JSON-LD headline", got)
+	}
+	if got := extractDescription(doc, "example.com"); got != "Structured" {
+		t.Errorf("extractDescription without meta tags = %q, want the JSON-LD description over the first paragraph", got)
+	}
+}
@@ -0,0 +1,69 @@
+// internal/fetcher/kind.go
+
+package fetcher
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Bookmark kinds inferred from a page's URL and content type.
+const (
+	KindPage     = "page"
+	KindVideo    = "video"
+	KindDocument = "document"
+	KindRepo     = "repo"
+	KindImage    = "image"
+)
+
+var videoHosts = []string{"youtube.com", "youtu.be", "vimeo.com", "twitch.tv", "dailymotion.com"}
+var repoHosts = []string{"github.com", "gitlab.com", "bitbucket.org", "codeberg.org"}
+var documentExtensions = []string{".pdf", ".doc", ".docx", ".epub", ".ppt", ".pptx", ".odt"}
+
+// InferKind classifies a bookmark from its URL and, when known, the Content-Type of
+// the response. The hostname wins over the content type, so a YouTube watch page is a
+// video rather than a page. It returns "" when nothing points to a kind.
+func InferKind(pageURL, contentType string) string {
+	if u, err := url.Parse(pageURL); err == nil {
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		switch {
+		case hostMatches(host, videoHosts):
+			return KindVideo
+		case hostMatches(host, repoHosts):
+			return KindRepo
+		}
+
+		ext := strings.ToLower(path.Ext(u.Path))
+		for _, documentExt := range documentExtensions {
+			if ext == documentExt {
+				return KindDocument
+			}
+		}
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "":
+		return ""
+	case mediaType == "application/pdf":
+		return KindDocument
+	case strings.HasPrefix(mediaType, "video/"):
+		return KindVideo
+	case strings.HasPrefix(mediaType, "image/"):
+		return KindImage
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return KindPage
+	}
+	return ""
+}
+
+// hostMatches reports whether host is one of hosts or a subdomain of one.
+func hostMatches(host string, hosts []string) bool {
+	for _, h := range hosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package fetcher
+
+import "testing"
+
+func TestInferKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		want        string
+	}{
+		{"youtube", "https://www.youtube.com/watch?v=abc", "text/html", KindVideo},
+		{"youtube short link", "https://youtu.be/abc", "", KindVideo},
+		{"github", "https://github.com/fallrising/goku", "text/html; charset=utf-8", KindRepo},
+		{"pdf by extension", "https://example.com/paper.PDF", "", KindDocument},
+		{"pdf by content type", "https://example.com/download?id=1", "application/pdf", KindDocument},
+		{"image", "https://example.com/cat", "image/png", KindImage},
+		{"html page", "https://example.com/", "text/html; charset=utf-8", KindPage},
+		{"unknown", "https://example.com/", "", ""},
+		{"lookalike host", "https://notgithub.com/x", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InferKind(tt.url, tt.contentType); got != tt.want {
+				t.Errorf("InferKind(%q, %q) = %q, want %q", tt.url, tt.contentType, got, tt.want)
+			}
+		})
+	}
+}
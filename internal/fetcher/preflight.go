@@ -0,0 +1,55 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// preflightNonHTML sends a HEAD request for pageURL and, when the response names a
+// media type other than HTML, returns a PageContent describing the resource without
+// downloading it. It returns nil when the body should be fetched: the resource is
+// HTML, its type is unknown, or the HEAD request failed.
+func preflightNonHTML(ctx context.Context, client *http.Client, pageURL string) *PageContent {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, pageURL, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// Servers without HEAD support answer 405 or 501; GET will tell
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return nil
+	}
+
+	return &PageContent{
+		Title:       titleFromURL(resp.Request.URL),
+		Description: fmt.Sprintf("Non-HTML resource (%s)", mediaType),
+		Kind:        InferKind(pageURL, contentType),
+		FinalURL:    resp.Request.URL.String(),
+		HTTPStatus:  resp.StatusCode,
+		Validators:  responseValidators(resp),
+	}
+}
+
+// titleFromURL names a resource by the last segment of its path, e.g. "report.pdf",
+// falling back to the host for URLs without one.
+func titleFromURL(u *url.URL) string {
+	name := path.Base(strings.TrimRight(u.Path, "/"))
+	if name == "." || name == "/" || name == "" {
+		return u.Hostname()
+	}
+	return name
+}
@@ -0,0 +1,74 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSkipNonHTML(t *testing.T) {
+	gets := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		switch r.URL.Path {
+		case "/papers/attention.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			fmt.Fprint(w, "%PDF-1.4")
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			fallthrough
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html><title>Page</title></html>")
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultFetchConfig()
+	config.AllowInternalIPs = true
+	config.SkipNonHTML = true
+	f := NewFetcher(config)
+
+	content, _, err := f.FetchPageContent(server.URL + "/papers/attention.pdf")
+	if err != nil {
+		t.Fatalf("FetchPageContent: %v", err)
+	}
+	if content.Title != "attention.pdf" || content.Description != "Non-HTML resource (application/pdf)" || content.Kind != KindDocument {
+		t.Errorf("PDF content = %+v", content)
+	}
+	if gets != 0 {
+		t.Errorf("PDF was downloaded %d time(s)", gets)
+	}
+
+	for _, path := range []string{"/page", "/no-head"} {
+		content, _, err := f.FetchPageContent(server.URL + path)
+		if err != nil {
+			t.Fatalf("FetchPageContent(%s): %v", path, err)
+		}
+		if content.Title != "Page" {
+			t.Errorf("%s: title = %q, want the page's", path, content.Title)
+		}
+	}
+}
+
+func TestTitleFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/files/report%20final.pdf": "report final.pdf",
+		"https://example.com/images/cat.png/":          "cat.png",
+		"https://example.com/":                         "example.com",
+		"https://example.com":                          "example.com",
+	}
+	for raw, want := range tests {
+		u, _ := url.Parse(raw)
+		if got := titleFromURL(u); got != want {
+			t.Errorf("titleFromURL(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// internal/fetcher/robots.go
+
+package fetcher
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// userAgentToken is the product token matched against robots.txt User-agent lines.
+const userAgentToken = "goku"
+
+// robotsRules is the part of a robots.txt that applies to goku.
+type robotsRules struct {
+	allow      []string
+	disallow   []string
+	crawlDelay time.Duration
+}
+
+// allowed reports whether path may be fetched. The longest matching rule wins, and
+// Allow wins a tie, as in RFC 9309.
+func (r *robotsRules) allowed(path string) bool {
+	longest := func(prefixes []string) int {
+		best := -1
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) && len(prefix) > best {
+				best = len(prefix)
+			}
+		}
+		return best
+	}
+	disallow := longest(r.disallow)
+	return disallow < 0 || longest(r.allow) >= disallow
+}
+
+// parseRobots reads the rules for goku from a robots.txt, falling back to the "*"
+// group when no group names goku.
+func parseRobots(r io.Reader) *robotsRules {
+	var specific, wildcard *robotsRules
+	var current []*robotsRules // groups the lines being read apply to
+	inAgents := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if !inAgents {
+				current = nil
+				inAgents = true
+			}
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case strings.Contains(agent, userAgentToken):
+				if specific == nil {
+					specific = &robotsRules{}
+				}
+				current = append(current, specific)
+			}
+			continue
+		}
+		inAgents = false
+
+		for _, rules := range current {
+			switch key {
+			case "allow":
+				if value != "" {
+					rules.allow = append(rules.allow, value)
+				}
+			case "disallow":
+				if value != "" {
+					rules.disallow = append(rules.disallow, value)
+				}
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					rules.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	switch {
+	case specific != nil:
+		return specific
+	case wildcard != nil:
+		return wildcard
+	}
+	return &robotsRules{}
+}
+
+// domainState is what the fetcher remembers about one host.
+type domainState struct {
+	robots          *robotsRules
+	robotsFetchedAt time.Time
+	lastRequest     time.Time
+}
+
+// domainStates holds per-host state shared by the fetcher's concurrent users.
+type domainStates struct {
+	mu     sync.Mutex
+	states map[string]*domainState
+}
+
+func (d *domainStates) get(host string) *domainState {
+	if d.states == nil {
+		d.states = make(map[string]*domainState)
+	}
+	state, ok := d.states[host]
+	if !ok {
+		state = &domainState{}
+		d.states[host] = state
+	}
+	return state
+}
+
+// robotsAllowed reports whether robots.txt on u's host lets goku fetch u. The file is
+// fetched once per host and kept for RobotsTTL. A missing or unreadable robots.txt
+// allows everything.
+func (f *Fetcher) robotsAllowed(u *url.URL) bool {
+	rules := f.robotsFor(u)
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return rules.allowed(path)
+}
+
+func (f *Fetcher) robotsFor(u *url.URL) *robotsRules {
+	f.domains.mu.Lock()
+	state := f.domains.get(u.Host)
+	if state.robots != nil && time.Since(state.robotsFetchedAt) < f.config.RobotsTTL {
+		rules := state.robots
+		f.domains.mu.Unlock()
+		return rules
+	}
+	f.domains.mu.Unlock()
+
+	rules := f.fetchRobots(u)
+
+	f.domains.mu.Lock()
+	state.robots = rules
+	state.robotsFetchedAt = time.Now()
+	f.domains.mu.Unlock()
+	return rules
+}
+
+func (f *Fetcher) fetchRobots(u *url.URL) *robotsRules {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+	resp, err := f.newClient(5 * time.Second).Get(robotsURL.String())
+	if err != nil {
+		return &robotsRules{}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsRules{}
+	}
+	return parseRobots(io.LimitReader(resp.Body, 512*1024))
+}
+
+// waitForDomain blocks until the host's Crawl-delay has passed since the previous
+// request to it, then records this request.
+func (f *Fetcher) waitForDomain(host string) {
+	f.domains.mu.Lock()
+	state := f.domains.get(host)
+	var delay time.Duration
+	if state.robots != nil {
+		delay = state.robots.crawlDelay
+	}
+	now := time.Now()
+	next := state.lastRequest.Add(delay)
+	if next.Before(now) {
+		next = now
+	}
+	// Reserve the slot before sleeping so concurrent callers queue up behind it
+	state.lastRequest = next
+	f.domains.mu.Unlock()
+
+	time.Sleep(time.Until(next))
+}
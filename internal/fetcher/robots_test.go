@@ -0,0 +1,76 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# comments are ignored
+User-agent: *
+Disallow: /private
+Allow: /private/open
+Crawl-delay: 2
+
+User-agent: goku
+User-agent: otherbot
+Disallow: /no-goku
+`
+
+func TestParseRobots(t *testing.T) {
+	rules := parseRobots(strings.NewReader(testRobots))
+	if len(rules.disallow) != 1 || rules.disallow[0] != "/no-goku" {
+		t.Fatalf("goku group not chosen over *: %+v", rules)
+	}
+
+	wildcardOnly := strings.Replace(testRobots, "User-agent: goku\n", "", 1)
+	rules = parseRobots(strings.NewReader(wildcardOnly))
+	if rules.crawlDelay != 2*time.Second {
+		t.Errorf("crawlDelay = %v, want 2s", rules.crawlDelay)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/private", false},
+		{"/private/secret", false},
+		{"/private/open/page", true},
+	}
+	for _, tt := range tests {
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRobotsAllowedCachesPerHost(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			requests++
+			fmt.Fprint(w, "User-agent: *\nDisallow: /blocked\n")
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultFetchConfig()
+	config.RespectRobots = true
+	f := NewFetcher(config)
+
+	for path, want := range map[string]bool{"/ok": true, "/blocked/page": false} {
+		u, _ := url.Parse(server.URL + path)
+		if got := f.robotsAllowed(u); got != want {
+			t.Errorf("robotsAllowed(%s) = %v, want %v", path, got, want)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", requests)
+	}
+}
@@ -8,13 +8,28 @@ import (
 
 type BookmarkRepository interface {
 	Create(ctx context.Context, bookmark *models.Bookmark) error
+	CreateBatch(ctx context.Context, bookmarks []*models.Bookmark) (int, error)
 	GetByID(ctx context.Context, id int64) (*models.Bookmark, error)
 	GetByURL(ctx context.Context, url string) (*models.Bookmark, error) // New method
 	Update(ctx context.Context, bookmark *models.Bookmark) error
 	Delete(ctx context.Context, id int64) error
+	RecordVisit(ctx context.Context, id int64) error
+	ListDeleted(ctx context.Context, limit, offset int) ([]*models.Bookmark, error)
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context) (int, error)
 	List(ctx context.Context, limit, offset int) ([]*models.Bookmark, error)
+	ListFiltered(ctx context.Context, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, error)
+	ListWithCount(ctx context.Context, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error)
+	ListRandom(ctx context.Context, limit int) ([]*models.Bookmark, error)
 	Search(ctx context.Context, query string, limit, offset int) ([]*models.Bookmark, error)
+	SearchFiltered(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, error)
+	SearchWithCount(ctx context.Context, query string, filter models.BookmarkFilter, limit, offset int) ([]*models.Bookmark, int, error)
 	ListAllTags(ctx context.Context) ([]string, error)
+	MergeTags(ctx context.Context, from []string, into string) (map[string]int, error)
+	PruneTags(ctx context.Context, dryRun bool) ([]string, error)
+	NormalizeTags(ctx context.Context, dryRun bool) (int, error)
+	GetFetchValidators(ctx context.Context, url string) (etag, lastModified string, err error)
+	SetFetchValidators(ctx context.Context, url, etag, lastModified string) error
 	// New methods for statistics
 	CountByHostname(ctx context.Context) (map[string]int, error)
 	CountByTag(ctx context.Context) (map[string]int, error)
@@ -25,4 +40,7 @@ type BookmarkRepository interface {
 	CountCreatedLastNDays(ctx context.Context, days int) (map[string]int, error)
 	Count(ctx context.Context) (int, error)
 	Purge(ctx context.Context) error
+	BackupTo(ctx context.Context, path string) error
+	RestoreFrom(ctx context.Context, path string) error
+	Close() error
 }
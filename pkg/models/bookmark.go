@@ -6,17 +6,56 @@ import (
 )
 
 type Bookmark struct {
-	ID          int64     `json:"id"`
-	URL         string    `json:"url"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Tags        []string  `json:"tags"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                 int64      `json:"id"`
+	URL                string     `json:"url"`
+	Title              string     `json:"title"`
+	Description        string     `json:"description"`
+	Tags               []string   `json:"tags"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	Kind               string     `json:"kind,omitempty"`                 // e.g. page, video, document, repo
+	CanonicalURL       string     `json:"canonical_url,omitempty"`        // from the page's rel="canonical" link
+	FaviconURL         string     `json:"favicon_url,omitempty"`          // set from fetched metadata; not stored yet
+	ImageURL           string     `json:"image_url,omitempty"`            // preview image from fetched metadata; not stored yet
+	Language           string     `json:"lang,omitempty"`                 // from <html lang> or og:locale, e.g. "en"
+	FeedURL            string     `json:"feed_url,omitempty"`             // the site's RSS or Atom feed, from the page's alternate links
+	ReadingTimeMinutes int        `json:"reading_time_minutes,omitempty"` // estimated from fetched metadata; not stored yet
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`           // when the bookmark was moved to the trash; nil otherwise
+	Favorite           bool       `json:"favorite,omitempty"`             // starred with goku favorite
+	VisitCount         int        `json:"visit_count,omitempty"`          // times recorded with goku visit
+	LastVisited        *time.Time `json:"last_visited,omitempty"`         // time of the last recorded visit; nil if never visited
+}
+
+// NormalizeTag returns tag in the form goku stores it: lowercased, trimmed, and with
+// runs of whitespace inside it collapsed to a single space.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// NormalizeTags normalizes each tag with NormalizeTag and drops empty and repeated
+// ones, keeping the first occurrence. Tags containing commas, which the tags column
+// cannot hold, are split at them.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]struct{})
+	var normalized []string
+	for _, raw := range tags {
+		for _, part := range strings.Split(raw, ",") {
+			tag := NormalizeTag(part)
+			if tag == "" {
+				continue
+			}
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+			seen[tag] = struct{}{}
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
 }
 
 func (b *Bookmark) AddTag(tag string) {
-	tag = strings.TrimSpace(strings.ToLower(tag))
+	tag = NormalizeTag(tag)
 	if tag == "" {
 		return
 	}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"already normal", []string{"go", "web dev"}, []string{"go", "web dev"}},
+		{"case and whitespace", []string{" Go ", "Web \t Dev"}, []string{"go", "web dev"}},
+		{"empty tags dropped", []string{"", "  ", "go"}, []string{"go"}},
+		{"duplicates keep first", []string{"go", "Go", "web", "GO"}, []string{"go", "web"}},
+		{"commas split", []string{"go,web", "db, sql"}, []string{"go", "web", "db", "sql"}},
+		{"nothing left", []string{",", " "}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTags(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BookmarkFilter narrows the bookmarks returned by list and search queries.
+// The zero value matches every bookmark outside the trash.
+type BookmarkFilter struct {
+	Tags           []string  // bookmarks must carry every one of these tags
+	AnyTags        bool      // bookmarks need only carry one of Tags
+	ExcludeTags    []string  // bookmarks must carry none of these tags
+	Host           string    // bookmarks must live on this host
+	StripWWW       bool      // match Host with and without a leading "www."
+	UpdatedSince   time.Time // bookmarks must have been updated at or after this time
+	Kind           string    // bookmarks must be of this kind, e.g. "video"
+	Language       string    // bookmarks must be in this language, e.g. "en" also matches "en-us"
+	Sort           SortField // order of the results; empty means ID order, or relevance for searches
+	SortDesc       bool      // reverse Sort, e.g. newest first for SortByCreated
+	IncludeTrashed bool      // also match bookmarks in the trash
+	OnlyTrashed    bool      // match only bookmarks in the trash; overrides IncludeTrashed
+	Favorites      bool      // match only bookmarks marked as favorites
+}
+
+// SortField names what listed bookmarks are ordered by. Ties are broken by ID, so a
+// listing of an unchanged library always comes back in the same order.
+type SortField string
+
+const (
+	SortByID      SortField = "id"      // insertion order
+	SortByCreated SortField = "created" // creation time, oldest first
+	SortByUpdated SortField = "updated" // time of the last change, oldest first
+	SortByTitle   SortField = "title"   // title, alphabetically and ignoring case
+	SortByURL     SortField = "url"     // URL, alphabetically
+	SortByVisits  SortField = "visits"  // number of recorded visits, fewest first
+)
+
+// SortFields lists every SortField, for validation and help texts.
+var SortFields = []SortField{SortByID, SortByCreated, SortByUpdated, SortByTitle, SortByURL, SortByVisits}
+
+// ParseSort reads a sort option of the form "field" or "field:direction", such as
+// "title" or "created:desc". The direction is "asc" (the default) or "desc".
+func ParseSort(value string) (field SortField, desc bool, err error) {
+	name, direction, _ := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+	switch direction {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return "", false, fmt.Errorf("unknown sort direction %q, want asc or desc", direction)
+	}
+	for _, field := range SortFields {
+		if string(field) == name {
+			return field, desc, nil
+		}
+	}
+	return "", false, fmt.Errorf("unknown sort field %q", name)
+}
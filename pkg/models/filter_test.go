@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		value string
+		field SortField
+		desc  bool
+	}{
+		{"created", SortByCreated, false},
+		{"created:desc", SortByCreated, true},
+		{" Title:ASC ", SortByTitle, false},
+		{"url:desc", SortByURL, true},
+	}
+	for _, tt := range tests {
+		field, desc, err := ParseSort(tt.value)
+		if err != nil || field != tt.field || desc != tt.desc {
+			t.Errorf("ParseSort(%q) = %q, %t, %v; want %q, %t", tt.value, field, desc, err, tt.field, tt.desc)
+		}
+	}
+
+	for _, value := range []string{"", "rank", "created:down", "title; DROP TABLE bookmarks"} {
+		if _, _, err := ParseSort(value); err == nil {
+			t.Errorf("ParseSort(%q) succeeded, want an error", value)
+		}
+	}
+}
@@ -1,16 +1,27 @@
 package models
 
 type Statistics struct {
-	HostnameCounts      map[string]int
-	TagCounts           map[string]int
-	LatestBookmarks     []*Bookmark
-	AccessibilityCounts map[string]int
-	TopHostnames        []HostnameCount
-	UniqueHostnames     []string
-	CreatedLastWeek     map[string]int
+	HostnameCounts      map[string]int  `json:"hostname_counts"`
+	TagCounts           map[string]int  `json:"tag_counts"`
+	LatestBookmarks     []*Bookmark     `json:"latest_bookmarks"`
+	AccessibilityCounts map[string]int  `json:"accessibility_counts"`
+	TopHostnames        []HostnameCount `json:"top_hostnames"`
+	UniqueHostnames     []string        `json:"unique_hostnames"`
+	// CreatedByDay maps YYYY-MM-DD to the number of bookmarks created that day,
+	// covering the window requested from GetStatistics.
+	CreatedByDay map[string]int `json:"created_by_day"`
 }
 
 type HostnameCount struct {
-	Hostname string
-	Count    int
+	Hostname string `json:"hostname"`
+	Count    int    `json:"count"`
+}
+
+// CountSummary is a compact set of library totals, cheap enough to scrape often.
+type CountSummary struct {
+	Total        int `json:"total"`
+	Accessible   int `json:"accessible"`
+	Inaccessible int `json:"inaccessible"`
+	Tags         int `json:"tags"`
+	Hosts        int `json:"hosts"`
 }
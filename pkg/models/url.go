@@ -0,0 +1,123 @@
+package models
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NormalizeURL returns the key used to compare URLs for duplicates. It lowercases the
+// scheme and host, and drops a leading "www." from the host when stripWWW is set.
+// URLs that cannot be parsed are returned unchanged.
+func NormalizeURL(raw string, stripWWW bool) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if stripWWW {
+		u.Host = strings.TrimPrefix(u.Host, "www.")
+	}
+
+	return u.String()
+}
+
+// EquivalentURLs returns the stored forms under which a bookmark for raw could already
+// exist: the URL itself, its normalized form and, when stripWWW is set, the normalized
+// form with a "www." host. Duplicates in the result are removed.
+func EquivalentURLs(raw string, stripWWW bool) []string {
+	candidates := []string{raw, NormalizeURL(raw, false)}
+	if stripWWW {
+		normalized := NormalizeURL(raw, true)
+		candidates = append(candidates, normalized)
+		if u, err := url.Parse(normalized); err == nil && u.Host != "" {
+			u.Host = "www." + u.Host
+			candidates = append(candidates, u.String())
+		}
+	}
+
+	seen := make(map[string]struct{})
+	var urls []string
+	for _, candidate := range candidates {
+		if _, ok := seen[candidate]; ok {
+			continue
+		}
+		seen[candidate] = struct{}{}
+		urls = append(urls, candidate)
+	}
+	return urls
+}
+
+// DefaultTrackingParams lists the query parameters CleanURL removes by default: the
+// ones analytics and ad platforms append to links. A trailing "*" matches any
+// parameter starting with the rest.
+var DefaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "igshid", "_hsenc", "_hsmi", "mkt_tok", "ref_src",
+}
+
+// CleanURL returns raw in the form goku stores it: scheme and host lowercased, the
+// default port for the scheme removed, trailing slashes dropped from the path, and
+// query parameters matching trackingParams removed. The remaining parameters are
+// sorted by name and keep their encoding. Cleaning a clean URL returns it unchanged;
+// URLs that cannot be parsed are returned unchanged too.
+func CleanURL(raw string, trackingParams []string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	type queryParam struct{ name, raw string }
+	var kept []queryParam
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !isTrackingParam(name, trackingParams) {
+			kept = append(kept, queryParam{name, param})
+		}
+	}
+	// Sorted so the same parameters in another order are the same URL. The sort is
+	// stable, as the order of a repeated parameter's values can matter.
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].name < kept[j].name })
+	params := make([]string, len(kept))
+	for i, param := range kept {
+		params[i] = param.raw
+	}
+	u.RawQuery = strings.Join(params, "&")
+	u.ForceQuery = false
+
+	return u.String()
+}
+
+// isTrackingParam reports whether the query parameter name matches one of
+// trackingParams, case-insensitively.
+func isTrackingParam(name string, trackingParams []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range trackingParams {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
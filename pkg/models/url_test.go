@@ -0,0 +1,91 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		stripWWW bool
+		want     string
+	}{
+		{"lowercases scheme and host", "HTTPS://Example.COM/Path", false, "https://example.com/Path"},
+		{"keeps www by default", "https://www.example.com/x", false, "https://www.example.com/x"},
+		{"strips www when asked", "https://www.example.com/x", true, "https://example.com/x"},
+		{"strips www case-insensitively", "https://WWW.Example.com/x", true, "https://example.com/x"},
+		{"leaves other subdomains alone", "https://blog.example.com/x", true, "https://blog.example.com/x"},
+		{"keeps port", "http://www.example.com:8080/", true, "http://example.com:8080/"},
+		{"returns hostless input unchanged", "not a url", true, "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeURL(tt.raw, tt.stripWWW); got != tt.want {
+				t.Errorf("NormalizeURL(%q, %v) = %q, want %q", tt.raw, tt.stripWWW, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEquivalentURLs(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		stripWWW bool
+		want     []string
+	}{
+		{"exact only without stripping", "https://example.com/x", false, []string{"https://example.com/x"}},
+		{"adds lowercased form", "https://Example.com/x", false, []string{"https://Example.com/x", "https://example.com/x"}},
+		{"adds www form", "https://example.com/x", true, []string{"https://example.com/x", "https://www.example.com/x"}},
+		{"adds bare form", "https://www.example.com/x", true, []string{"https://www.example.com/x", "https://example.com/x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EquivalentURLs(tt.raw, tt.stripWWW); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EquivalentURLs(%q, %v) = %v, want %v", tt.raw, tt.stripWWW, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"drops utm params", "https://example.com/post?utm_source=x&id=7&utm_medium=email", "https://example.com/post?id=7"},
+		{"drops click ids", "https://example.com/?fbclid=abc&gclid=def", "https://example.com"},
+		{"matches case-insensitively", "https://example.com/a?UTM_Source=x&Q=go", "https://example.com/a?Q=go"},
+		{"sorts params and keeps encoding", "https://example.com/s?q=a%20b&page=2&utm_term=x&sort=new", "https://example.com/s?page=2&q=a%20b&sort=new"},
+		{"keeps repeated params in order", "https://example.com/s?tag=go&a=1&tag=db", "https://example.com/s?a=1&tag=go&tag=db"},
+		{"sorts by unescaped name", "https://example.com/s?%62=2&a=1", "https://example.com/s?a=1&%62=2"},
+		{"lowercases scheme and host", "HTTPS://Example.COM/Path/", "https://example.com/Path"},
+		{"drops default https port", "https://example.com:443/x", "https://example.com/x"},
+		{"drops default http port", "http://example.com:80/x", "http://example.com/x"},
+		{"keeps other ports", "https://example.com:8443/x", "https://example.com:8443/x"},
+		{"keeps fragment", "https://example.com/doc/?utm_campaign=y#intro", "https://example.com/doc#intro"},
+		{"returns hostless input unchanged", "not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CleanURL(tt.raw, DefaultTrackingParams)
+			if got != tt.want {
+				t.Errorf("CleanURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+			if again := CleanURL(got, DefaultTrackingParams); again != got {
+				t.Errorf("CleanURL is not idempotent: %q became %q", got, again)
+			}
+		})
+	}
+
+	custom := append(DefaultTrackingParams, "ref", "share_*")
+	if got, want := CleanURL("https://example.com/a?ref=hn&share_id=1&id=2", custom), "https://example.com/a?id=2"; got != want {
+		t.Errorf("with custom params: got %q, want %q", got, want)
+	}
+}